@@ -42,7 +42,7 @@ func EncodeRequest(username string, password string, uuid *string, ownerUuid *st
 		message.AppendStr(uuid)
 	}
 	message.AppendBool(ownerUuid == nil)
-	if uuid != nil{
+	if ownerUuid != nil{
 		message.AppendStr(ownerUuid)
 	}
 	message.AppendBool(isOwnerConnection)
@@ -0,0 +1,49 @@
+package hz
+
+func CalculateCustomSize(credentials []byte, uuid *string, ownerUuid *string, isOwnerConnection bool, clientType string, serializationVersion uint8) int {
+
+	dataSize := 0
+	dataSize += INT_SIZE_IN_BYTES + len(credentials)
+	dataSize += BOOLEAN_SIZE_IN_BYTES
+	if uuid != nil {
+		dataSize += CalculateSizeStr(uuid)
+	}
+	dataSize += BOOLEAN_SIZE_IN_BYTES
+	if ownerUuid != nil {
+		dataSize += CalculateSizeStr(ownerUuid)
+	}
+	dataSize += BOOLEAN_SIZE_IN_BYTES
+	dataSize += CalculateSizeStr(&clientType)
+	dataSize += BYTE_SIZE_IN_BYTES
+
+	return dataSize
+}
+
+func EncodeCustomRequest(credentials []byte, uuid *string, ownerUuid *string, isOwnerConnection bool, clientType string, serializationVersion uint8) *ClientMessage {
+
+	payloadSize := CalculateCustomSize(credentials, uuid, ownerUuid, isOwnerConnection, clientType, serializationVersion)
+	message := CreateForEncode(payloadSize)
+	message.SetMessageType(CLIENT_AUTHENTICATIONCUSTOM)
+	message.SetIsRetryable(true)
+	message.AppendByteArray(credentials)
+	message.AppendBool(uuid == nil)
+	if uuid != nil {
+		message.AppendStr(uuid)
+	}
+	message.AppendBool(ownerUuid == nil)
+	if ownerUuid != nil {
+		message.AppendStr(ownerUuid)
+	}
+	message.AppendBool(isOwnerConnection)
+	message.AppendStr(&clientType)
+	message.AppendByte(serializationVersion)
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// The custom authentication response has the same layout as the username/password one
+func DecodeCustomResponse(message *ClientMessage) *ResponseParameters {
+
+	return DecodeResponse(message)
+}
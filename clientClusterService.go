@@ -0,0 +1,294 @@
+package hz
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+const (
+
+	MEMBER_ADDED = 1
+	MEMBER_REMOVED = 2
+	MEMBER_ATTRIBUTE_CHANGED = 3
+
+	MEMBER_ATTRIBUTE_PUT = 1
+	MEMBER_ATTRIBUTE_REMOVE = 2
+)
+
+// A cluster topology change, the attribute fields are only set for MEMBER_ATTRIBUTE_CHANGED
+type MembershipEvent struct {
+
+	EventType int32
+	Member    Member
+	Members   []Member
+
+	AttributeKey           string
+	AttributeOperationType int32
+	AttributeValue         *string
+}
+
+// Tracks the cluster member list through the membership listener on the owner connection
+type ClusterService struct {
+
+	invocationService *InvocationService
+
+	mutex            sync.RWMutex
+	members          []Member
+	listeners        map[string]func(MembershipEvent)
+	nextListenerId   int
+	memberListLoaded chan struct{}
+
+	registrationId string
+	stopOnce       *sync.Once
+	stop           chan struct{}
+
+	Logger ILogging
+}
+
+func NewClusterService(invocationService *InvocationService) *ClusterService {
+
+	service := new(ClusterService)
+	service.invocationService = invocationService
+	service.listeners = make(map[string]func(MembershipEvent))
+	service.memberListLoaded = make(chan struct{})
+	service.stopOnce = &sync.Once{}
+	service.stop = make(chan struct{})
+	service.Logger = invocationService.Logger
+
+	return service
+}
+
+// Register the membership listener on the owner connection and wait for the initial member list
+func (this *ClusterService) Start(ctx context.Context) error {
+
+	id, cb, err := this.invocationService.listenerService.RegisterListener(ctx, func() *ClientMessage {
+		return EncodeAddMembershipListenerRequest(false)
+	}, RESPONSE_STRING)
+	if err != nil {
+		return err
+	}
+
+	this.registrationId = id
+
+	go func() {
+		for {
+			select {
+			case <-this.stop:
+				return
+			case event := <-cb.NotifyChannel:
+				this.handleEvent(event)
+			}
+		}
+	}()
+
+	select {
+	case <-this.memberListLoaded:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (this *ClusterService) Shutdown() {
+
+	this.stopOnce.Do(func() {
+		close(this.stop)
+		if this.registrationId != "" {
+			this.invocationService.listenerService.DeregisterListener(context.Background(), this.registrationId, nil)
+		}
+	})
+}
+
+func (this *ClusterService) handleEvent(event *ClientMessage) {
+
+	switch event.GetMessageType() {
+	case EVENT_MEMBERLIST:
+		this.memberList(DecodeMemberListEvent(event))
+	case EVENT_MEMBER:
+		member, eventType := DecodeMemberEvent(event)
+		if eventType == MEMBER_ADDED {
+			this.memberAdded(member)
+		} else if eventType == MEMBER_REMOVED {
+			this.memberRemoved(member)
+		}
+	case EVENT_MEMBERATTRIBUTECHANGE:
+		uuid, key, operationType, value := DecodeMemberAttributeChangeEvent(event)
+		this.memberAttributeChanged(uuid, key, operationType, value)
+	default:
+		this.Logger.Warn("Unexpected membership event type: 0x%04x", event.GetMessageType())
+	}
+}
+
+// A full member list replaces the current one, differences are published as added and removed events
+func (this *ClusterService) memberList(members []Member) {
+
+	this.mutex.Lock()
+
+	previous := this.members
+	this.members = members
+
+	select {
+	case <-this.memberListLoaded:
+	default:
+		close(this.memberListLoaded)
+	}
+
+	this.mutex.Unlock()
+
+	this.Logger.Info("Cluster members: %v", members)
+
+	for _, member := range previous {
+		if indexOfMember(members, member.Uuid) < 0 {
+			this.publish(MembershipEvent{EventType: MEMBER_REMOVED, Member: member, Members: members})
+		}
+	}
+	for _, member := range members {
+		if indexOfMember(previous, member.Uuid) < 0 {
+			this.publish(MembershipEvent{EventType: MEMBER_ADDED, Member: member, Members: members})
+		}
+	}
+}
+
+func (this *ClusterService) memberAdded(member Member) {
+
+	this.mutex.Lock()
+	members := append(append([]Member{}, this.members...), member)
+	this.members = members
+	this.mutex.Unlock()
+
+	this.Logger.Info("Member added: %v", member)
+	this.publish(MembershipEvent{EventType: MEMBER_ADDED, Member: member, Members: members})
+}
+
+func (this *ClusterService) memberRemoved(member Member) {
+
+	this.mutex.Lock()
+	members := make([]Member, 0, len(this.members))
+	for _, existing := range this.members {
+		if existing.Uuid != member.Uuid {
+			members = append(members, existing)
+		}
+	}
+	this.members = members
+	this.mutex.Unlock()
+
+	this.Logger.Info("Member removed: %v", member)
+	this.publish(MembershipEvent{EventType: MEMBER_REMOVED, Member: member, Members: members})
+}
+
+func (this *ClusterService) memberAttributeChanged(uuid string, key string, operationType int32, value *string) {
+
+	this.mutex.Lock()
+	index := indexOfMember(this.members, uuid)
+	if index < 0 {
+		this.mutex.Unlock()
+		this.Logger.Warn("Attribute change for unknown member: %s", uuid)
+		return
+	}
+
+	// Copy on write so member lists already handed out are not modified
+	member := this.members[index]
+	attributes := make(map[string]string, len(member.Attributes))
+	for k, v := range member.Attributes {
+		attributes[k] = v
+	}
+	if operationType == MEMBER_ATTRIBUTE_PUT && value != nil {
+		attributes[key] = *value
+	} else {
+		delete(attributes, key)
+	}
+	member.Attributes = attributes
+
+	members := append([]Member{}, this.members...)
+	members[index] = member
+	this.members = members
+	this.mutex.Unlock()
+
+	this.publish(MembershipEvent{EventType: MEMBER_ATTRIBUTE_CHANGED, Member: member, Members: members,
+		AttributeKey: key, AttributeOperationType: operationType, AttributeValue: value})
+}
+
+func (this *ClusterService) publish(event MembershipEvent) {
+
+	this.mutex.RLock()
+	listeners := make([]func(MembershipEvent), 0, len(this.listeners))
+	for _, listener := range this.listeners {
+		listeners = append(listeners, listener)
+	}
+	this.mutex.RUnlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// The current member list, in cluster order
+func (this *ClusterService) GetMembers() []Member {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	return this.members
+}
+
+func (this *ClusterService) GetMember(uuid string) *Member {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	index := indexOfMember(this.members, uuid)
+	if index < 0 {
+		return nil
+	}
+	member := this.members[index]
+
+	return &member
+}
+
+// The listener is called on the event goroutine and should not block, returns the id used for removal
+func (this *ClusterService) AddMembershipListener(listener func(MembershipEvent)) string {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.nextListenerId++
+	id := strconv.Itoa(this.nextListenerId)
+	this.listeners[id] = listener
+
+	return id
+}
+
+// Deliver events to the channel. Events are dropped with a warning when the channel is full
+func (this *ClusterService) AddMembershipChannel(channel chan<- MembershipEvent) string {
+
+	return this.AddMembershipListener(func(event MembershipEvent) {
+		select {
+		case channel <- event:
+		default:
+			this.Logger.Warn("Membership channel full, dropped event: %d for %v", event.EventType, event.Member)
+		}
+	})
+}
+
+func (this *ClusterService) RemoveMembershipListener(id string) bool {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	_, ok := this.listeners[id]
+	delete(this.listeners, id)
+
+	return ok
+}
+
+func indexOfMember(members []Member, uuid string) int {
+
+	for i, member := range members {
+		if member.Uuid == uuid {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,65 @@
+package hz
+
+import (
+	"testing"
+	"time"
+)
+
+func memberUuids(members []Member) []string {
+
+	uuids := make([]string, len(members))
+	for i, member := range members {
+		uuids[i] = member.Uuid
+	}
+
+	return uuids
+}
+
+func TestMembershipEvents(t *testing.T) {
+
+	client, server := newStubClient(t, func(request *ClientMessage) []*ClientMessage { return nil })
+
+	events := make(chan MembershipEvent, 4)
+	client.ClusterService().AddMembershipChannel(events)
+
+	joined := Address{Host: "127.0.0.1", Port: 5702}
+
+	next := func() MembershipEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("no membership event")
+		}
+		return MembershipEvent{}
+	}
+
+	server.pushMembershipEvent(t, memberEvent(joined, "joined-member", MEMBER_ADDED))
+
+	event := next()
+	if event.EventType != MEMBER_ADDED || event.Member.Uuid != "joined-member" || event.Member.Address != joined {
+		t.Fatalf("got %+v", event)
+	}
+	if got := memberUuids(event.Members); len(got) != 2 || got[0] != "stub-member" || got[1] != "joined-member" {
+		t.Fatalf("event member list: %v", got)
+	}
+	if got := memberUuids(client.ClusterService().GetMembers()); len(got) != 2 {
+		t.Fatalf("member list after join: %v", got)
+	}
+	if member := client.ClusterService().GetMember("joined-member"); member == nil || member.Address != joined {
+		t.Fatalf("joined member: %v", member)
+	}
+
+	server.pushMembershipEvent(t, memberEvent(server.address, "stub-member", MEMBER_REMOVED))
+
+	event = next()
+	if event.EventType != MEMBER_REMOVED || event.Member.Uuid != "stub-member" {
+		t.Fatalf("got %+v", event)
+	}
+	if got := memberUuids(client.ClusterService().GetMembers()); len(got) != 1 || got[0] != "joined-member" {
+		t.Fatalf("member list after leave: %v", got)
+	}
+	if client.ClusterService().GetMember("stub-member") != nil {
+		t.Fatal("removed member still listed")
+	}
+}
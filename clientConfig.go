@@ -0,0 +1,104 @@
+package hz
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+
+	DEFAULT_CONNECTION_ATTEMPT_LIMIT = 2 // as the Java client
+	DEFAULT_CONNECTION_RETRY_PAUSE = 1 * time.Second
+	DEFAULT_CONNECTION_MAX_RETRY_PAUSE = 30 * time.Second
+	DEFAULT_CONNECTION_BACKOFF_MULTIPLIER = 2.0
+	DEFAULT_CLUSTER_START_TIMEOUT = 30 * time.Second
+	DEFAULT_HEARTBEAT_INTERVAL = 5 * time.Second
+	DEFAULT_HEARTBEAT_TIMEOUT = 60 * time.Second
+)
+
+type ClientConfig struct {
+
+	// Cluster members to try, in order, for the owner connection
+	Addresses []Address
+
+	GroupName     string
+	GroupPassword string
+
+	// Used instead of GroupName and GroupPassword when set
+	Credentials Credentials
+
+	Logger ILogging
+
+	InvocationConfig *InvocationConfig
+
+	// Rounds through Addresses before giving up on (re)connecting, 0 for no limit
+	ConnectionAttemptLimit int
+
+	// Pause after a failed round through Addresses, multiplied by ConnectionBackoffMultiplier after each round up to ConnectionMaxRetryPause
+	ConnectionRetryPause        time.Duration
+	ConnectionMaxRetryPause     time.Duration
+	ConnectionBackoffMultiplier float64
+
+	// Time allowed to load the partition table and member list once connected
+	ClusterStartTimeout time.Duration
+
+	PartitionRefreshInterval time.Duration
+
+	// Ping idle connections every HeartbeatInterval, 0 to disable. A connection without data received for HeartbeatTimeout is closed,
+	// which for the owner connection triggers a reconnect
+	HeartbeatInterval time.Duration
+	HeartbeatTimeout  time.Duration
+
+	// Connect with TLS when set, required by members with TLS/SSL enabled
+	TLSConfig *TLSConfig
+
+	// Outgoing messages larger than this are sent in fragments, must be greater than HEADER_SIZE
+	MaxFrameSize int
+
+	SerializationConfig *SerializationConfig
+}
+
+func NewClientConfig() *ClientConfig {
+
+	config := new(ClientConfig)
+	config.Addresses = []Address{{Host: "localhost", Port: 5701}}
+	config.GroupName = "dev"
+	config.GroupPassword = "dev-pass"
+	config.InvocationConfig = NewInvocationConfig()
+	config.ConnectionAttemptLimit = DEFAULT_CONNECTION_ATTEMPT_LIMIT
+	config.ConnectionRetryPause = DEFAULT_CONNECTION_RETRY_PAUSE
+	config.ConnectionMaxRetryPause = DEFAULT_CONNECTION_MAX_RETRY_PAUSE
+	config.ConnectionBackoffMultiplier = DEFAULT_CONNECTION_BACKOFF_MULTIPLIER
+	config.ClusterStartTimeout = DEFAULT_CLUSTER_START_TIMEOUT
+	config.PartitionRefreshInterval = DEFAULT_PARTITION_REFRESH_INTERVAL
+	config.HeartbeatInterval = DEFAULT_HEARTBEAT_INTERVAL
+	config.HeartbeatTimeout = DEFAULT_HEARTBEAT_TIMEOUT
+	config.MaxFrameSize = DEFAULT_MAX_FRAME_SIZE
+	config.SerializationConfig = NewSerializationConfig()
+
+	return config
+}
+
+// The credentials to authenticate with, a CustomCredentials Value is serialized with the given service
+func (this *ClientConfig) credentials(service *SerializationService) (Credentials, error) {
+
+	if custom, ok := this.Credentials.(*CustomCredentials); ok {
+		return custom.serialize(service)
+	}
+
+	if this.Credentials != nil {
+		return this.Credentials, nil
+	}
+
+	return &UsernamePasswordCredentials{Username: this.GroupName, Password: this.GroupPassword}, nil
+}
+
+func (this *ClientConfig) validate() error {
+
+	if this.MaxFrameSize <= HEADER_SIZE {
+		return errors.New(fmt.Sprintf("Invalid MaxFrameSize: %d, must be greater than the header size: %d", this.MaxFrameSize, HEADER_SIZE))
+	}
+
+	return nil
+}
@@ -1,12 +1,16 @@
 package hz
 
 import (
+	"context"
+	"crypto/tls"
+	"io"
 	"net"
 	"fmt"
 	"errors"
 	"strconv"
 	"encoding/binary"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,11 +24,79 @@ type ILogging interface {
 	Fatal(string, ...interface{})
 }
 
+// Used when no logger has been configured
+type noLogging struct{}
+
+func (noLogging) Trace(string, ...interface{}) {}
+func (noLogging) Info(string, ...interface{})  {}
+func (noLogging) Warn(string, ...interface{})  {}
+func (noLogging) Error(string, ...interface{}) {}
+func (noLogging) Fatal(string, ...interface{}) {}
+
 // Callback data as all responses are async
 type ResponseCallback struct {
 
 	NotifyChannel chan *ClientMessage
 	autoRemove    bool
+
+	// Listener events waiting for NotifyChannel, moved there in order by a single drain goroutine
+	queueMutex sync.Mutex
+	queue      []*ClientMessage
+	queued     chan struct{}
+	removed    chan struct{}
+}
+
+// Queue a listener event without blocking the read loop
+func (this *ResponseCallback) enqueue(msg *ClientMessage) {
+
+	this.queueMutex.Lock()
+	this.queue = append(this.queue, msg)
+	this.queueMutex.Unlock()
+
+	select {
+	case this.queued <- struct{}{}:
+	default:
+	}
+}
+
+func (this *ResponseCallback) dequeue() *ClientMessage {
+
+	this.queueMutex.Lock()
+	defer this.queueMutex.Unlock()
+
+	if len(this.queue) == 0 {
+		return nil
+	}
+
+	msg := this.queue[0]
+	this.queue[0] = nil
+	this.queue = this.queue[1:]
+
+	return msg
+}
+
+// Deliver queued events until the listener is removed or the connection closes
+func (this *ResponseCallback) drain(closed chan struct{}) {
+
+	for {
+		select {
+		case <-this.removed:
+			return
+		case <-closed:
+			return
+		case <-this.queued:
+		}
+
+		for msg := this.dequeue(); msg != nil; msg = this.dequeue() {
+			select {
+			case this.NotifyChannel <- msg:
+			case <-this.removed:
+				return
+			case <-closed:
+				return
+			}
+		}
+	}
 }
 
 type ClientConnection struct {
@@ -32,22 +104,50 @@ type ClientConnection struct {
 	Address        Address
 	readBuffer     []byte
 	cid            uint64
-	partitionCount int32
 	socketMutex    *sync.Mutex
 	socket         net.Conn
 
+	// Set by SendPartitions for the Send* functions, clients route with their PartitionService instead
+	partitionCount atomic.Int32
+
 	responsesMutex *sync.Mutex
 	responses      map[int64]*ResponseCallback
+	listeners      map[int64]*ResponseCallback
+	fragments      map[int64]*ClientMessage
+
+	// Outgoing messages larger than this are split into BEGIN/END fragments
+	MaxFrameSize int
+
+	// Dial with TLS when set
+	TLSConfig *TLSConfig
 
 	Logger ILogging
 	Closed bool
 
-	QueueSerializerId uint32
+	closeOnce    *sync.Once
+	closed       chan struct{}
+	readLoopOnce *sync.Once
+
+	// Confirmed by the member during authentication, always the client serialization version
+	SerializationVersion uint8
+
+	// Unix nanos of the last completed socket read and write
+	lastRead  int64
+	lastWrite int64
 }
 
+var (
+
+	ErrConnectionClosed = errors.New("Connection is closed")
+	ErrExchangeTimeout = errors.New("Message exchange timeout")
+)
+
 const (
 
 	DEFAULT_EXCHANGE_TIMEOUT_MILLIS = 1000 * 60 * 2 // 2 mins
+	DEFAULT_EXCHANGE_TIMEOUT = DEFAULT_EXCHANGE_TIMEOUT_MILLIS * time.Millisecond
+	DEFAULT_MAX_FRAME_SIZE = 1024 * 1024 // 1MB
+	LISTENER_EVENT_BUFFER_SIZE = 128
 )
 
 func NewClientConnection(address Address) *ClientConnection {
@@ -58,8 +158,13 @@ func NewClientConnection(address Address) *ClientConnection {
 	connection.socketMutex = &sync.Mutex{}
 	connection.responsesMutex = &sync.Mutex{}
 	connection.responses = make(map[int64]*ResponseCallback)
+	connection.listeners = make(map[int64]*ResponseCallback)
+	connection.fragments = make(map[int64]*ClientMessage)
+	connection.MaxFrameSize = DEFAULT_MAX_FRAME_SIZE
+	connection.closeOnce = &sync.Once{}
+	connection.readLoopOnce = &sync.Once{}
+	connection.closed = make(chan struct{})
 	connection.cid = 1
-	connection.QueueSerializerId = 0
 
 	return connection
 }
@@ -67,16 +172,31 @@ func NewClientConnection(address Address) *ClientConnection {
 // Ensure a unique id on each message exchange
 func (this *ClientConnection) NextCorrelationId() uint64 {
 
-	this.cid += 1
-
-	return this.cid
+	return atomic.AddUint64(&this.cid, 1)
 }
 
+// Close the socket, any exchange still waiting for a response fails with ErrConnectionClosed
 func (this *ClientConnection) Close() {
 
-	this.Logger.Trace("Closing connection: %v", this.socket)
-	this.socket.Close()
-	this.Closed = true;
+	this.closeOnce.Do(func() {
+		this.Logger.Trace("Closing connection: %v", this.socket)
+		if this.socket != nil {
+			this.socket.Close()
+		}
+		this.Closed = true;
+		close(this.closed)
+	})
+}
+
+// Safe for concurrent use, unlike reading the Closed field
+func (this *ClientConnection) IsClosed() bool {
+
+	select {
+	case <-this.closed:
+		return true
+	default:
+		return false
+	}
 }
 
 func (this *ClientConnection) Connect(address Address) *Promise {
@@ -87,107 +207,196 @@ func (this *ClientConnection) Connect(address Address) *Promise {
 	result.FailureChannel = make(chan error, 1)
 
 	go func() {
-		socket, err := net.Dial("tcp", this.Address.Host+":"+strconv.Itoa(this.Address.Port))
+		socket, err := this.dial()
 		this.socket = socket
 		if err == nil {
 			this.Closed = false
+			atomic.StoreInt64(&this.lastRead, time.Now().UnixNano())
 			this.socket.Write([]byte(CLIENT_BINARY_NEW))
 			result.SuccessChannel <- this
 		} else {
-			result.FailureChannel <- errors.New(fmt.Sprintf("Could not connect to address: %s, err(%v)", this.Address.String(), err))
+			result.FailureChannel <- fmt.Errorf("Could not connect to address: %s, err(%w)", this.Address.String(), err)
 		}
 	}()
 
 	return result
 }
 
-// A single socket read loop with message distribution to registered callbacks
+// Plain TCP, or a completed TLS handshake when TLSConfig is set
+func (this *ClientConnection) dial() (net.Conn, error) {
+
+	hostPort := this.Address.Host+":"+strconv.Itoa(this.Address.Port)
+
+	if this.TLSConfig == nil {
+		return net.Dial("tcp", hostPort)
+	}
+
+	socket, err := tls.Dial("tcp", hostPort, this.TLSConfig.toTLS(this.Address.Host))
+	if err != nil {
+		return nil, err
+	}
+
+	return socket, nil
+}
+
+// A single socket read loop with message distribution to registered callbacks, only the first call starts it
 func (this *ClientConnection) InitReadLoop() {
 
-	go func() {
+	this.readLoopOnce.Do(this.startReadLoop)
+}
 
-		flBuffer := make([]byte, INT_SIZE_IN_BYTES)
+func (this *ClientConnection) startReadLoop() {
+
+	go func() {
 
 		for {
-			i, err := this.socket.Read(flBuffer)
+			frame, err := this.readFrame()
 			if nil != err {
-				this.Logger.Error("Unexpected error reading frame length! %v - read loop aborted!", err)
-				return;
-			}
-			for i < len(flBuffer) {
-				j, err2 := this.socket.Read(flBuffer[i:])
-				if nil != err2 {
-					this.Logger.Error("Unexpected error reading frame length! %v - read loop aborted!", err)
-					return;
+				if !this.IsClosed() {
+					this.Logger.Error("Unexpected error reading frame! %v - read loop aborted!", err)
+					this.Close()
 				}
-				i = i + j
-			}
-			frameLength := binary.LittleEndian.Uint32(flBuffer[0:])
-			fBuffer := make([]byte, frameLength-INT_SIZE_IN_BYTES)
-
-			i, err = this.socket.Read(fBuffer)
-			if nil != err {
-				this.Logger.Error("Unexpected error reading message! %v - read loop aborted!", err)
+				// Partial messages can no longer complete
+				this.fragments = make(map[int64]*ClientMessage)
 				return;
 			}
-			if i < len(fBuffer) {
-				j, err2 := this.socket.Read(fBuffer[i:])
-				if nil != err2 {
-					this.Logger.Error("Unexpected error reading message! %v - read loop aborted!", err)
-					return;
-				}
-				i = i + j
+
+			msg := this.assemble(frame)
+			if msg != nil {
+				this.dispatch(msg)
 			}
+		}
+	}()
+}
+
+// Read one complete frame from the socket
+func (this *ClientConnection) readFrame() (*ClientMessage, error) {
 
-			msg := new(ClientMessage)
-			msg.Buffer = append(flBuffer, fBuffer...)
+	flBuffer := make([]byte, INT_SIZE_IN_BYTES)
+	if _, err := io.ReadFull(this.socket, flBuffer); nil != err {
+		return nil, err
+	}
 
-			cid := msg.GetCorrelationId()
+	atomic.StoreInt64(&this.lastRead, time.Now().UnixNano())
 
-			this.responsesMutex.Lock()
-			cb, ok := this.responses[cid]
-			if ok {
-				if cb.autoRemove {
-					delete(this.responses, cid)
-					this.Logger.Trace("Removed correlation id from responses map: %d", cid)
-				}
-				this.responsesMutex.Unlock()
-				go func() {
-					cb.NotifyChannel <- msg
-				}()
-			} else {
-				this.Logger.Error("Failed to find correlation id: %d using response message of type: 0x%04x! Message receiver is now BLOCKED!!", cid, msg.GetMessageType())
-				this.responsesMutex.Unlock()
+	frameLength := binary.LittleEndian.Uint32(flBuffer[0:])
+	if frameLength < HEADER_SIZE {
+		return nil, errors.New(fmt.Sprintf("Invalid frame length: %d", frameLength))
+	}
+
+	buffer := make([]byte, frameLength)
+	copy(buffer, flBuffer)
+	if _, err := io.ReadFull(this.socket, buffer[INT_SIZE_IN_BYTES:]); nil != err {
+		return nil, err
+	}
+
+	return CreateForDecode(buffer), nil
+}
+
+// Reassemble fragmented messages keyed by correlation id, returns nil until the END frame arrives
+func (this *ClientConnection) assemble(frame *ClientMessage) *ClientMessage {
+
+	if frame.HasFlags(BEGIN_END_FLAG) == BEGIN_END_FLAG {
+		return frame
+	}
+
+	cid := frame.GetCorrelationId()
+
+	if frame.HasFlags(BEGIN_FLAG) != 0 {
+		this.fragments[cid] = frame
+		return nil
+	}
+
+	msg, ok := this.fragments[cid]
+	if !ok {
+		this.Logger.Error("Discarding fragment without BEGIN frame for correlation id: %d", cid)
+		return nil
+	}
+
+	msg.Accumulate(frame)
+	if !msg.IsComplete() {
+		return nil
+	}
+
+	delete(this.fragments, cid)
+	this.Logger.Trace("Reassembled fragmented message: cid=%d, framelength=%d", cid, msg.GetFrameLength())
+
+	return msg
+}
+
+func (this *ClientConnection) dispatch(msg *ClientMessage) {
+
+	cid := msg.GetCorrelationId()
+
+	this.responsesMutex.Lock()
+	cb, ok := this.responses[cid]
+	if msg.HasFlags(LISTENER_FLAG) != 0 {
+		if listener, found := this.listeners[cid]; found {
+			cb, ok = listener, true
+		}
+	}
+	if ok {
+		if cb.autoRemove {
+			delete(this.responses, cid)
+			this.Logger.Trace("Removed correlation id from responses map: %d", cid)
+		}
+		this.responsesMutex.Unlock()
+		if cb.queued != nil {
+			cb.enqueue(msg)
+		} else {
+			// A response callback expects a single message, never block the read loop on a second one
+			select {
+			case cb.NotifyChannel <- msg:
+			default:
+				this.Logger.Error("Discarding message of type: 0x%04x for correlation id: %d, the previous one was not yet received", msg.GetMessageType(), cid)
 			}
 		}
-	}()
+	} else {
+		this.Logger.Error("Failed to find correlation id: %d using response message of type: 0x%04x! Message receiver is now BLOCKED!!", cid, msg.GetMessageType())
+		this.responsesMutex.Unlock()
+	}
 }
 
 func (this *ClientConnection) Exchange(msg *ClientMessage) (*ClientMessage, error) {
 
-	return this.ExchangeWithTimeout(msg, DEFAULT_EXCHANGE_TIMEOUT_MILLIS)
+	return this.ExchangeWithTimeout(msg, DEFAULT_EXCHANGE_TIMEOUT)
 }
 
 func (this *ClientConnection) ExchangeWithTimeout(msg *ClientMessage, timeout time.Duration) (*ClientMessage, error) {
 
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return this.ExchangeWithContext(ctx, msg)
+}
+
+// Send the message and wait for its response until the context is done
+func (this *ClientConnection) ExchangeWithContext(ctx context.Context, msg *ClientMessage) (*ClientMessage, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	this.socketMutex.Lock()
 
-	cb := this.Register(msg.GetCorrelationId())
+	if this.IsClosed() {
+		this.socketMutex.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrConnectionClosed, this.Address.String())
+	}
+
+	cid := msg.GetCorrelationId()
+	cb := this.Register(cid)
 	cb.autoRemove = true
 
 	this.Logger.Trace("====> Sending: cid=%d, type=0x%02x, partitionid=%d, framelength=%d, flags=0x%02x, dataoffset=%d", msg.GetCorrelationId(), msg.GetMessageType(), msg.GetPartitionId(), msg.GetFrameLength(), msg.GetFlags(), msg.GetDataOffset())
 
-	n, err := this.socket.Write(msg.Buffer)
+	err := this.writeMessage(msg)
 	if nil != err {
 		this.socketMutex.Unlock()
+		this.Unregister(cid)
 		this.Logger.Error("Fatal socket error on write: %v\n", err)
 		this.Close()
-		return nil, err
-	}
-	if n != len(msg.Buffer) {
-		this.socketMutex.Unlock()
-		this.Close()
-		return nil, errors.New(fmt.Sprintf("Fatal socket error: Incomplete write to socket! buffer size=%d, written=%d\r\n", len(msg.Buffer), n))
+		return nil, fmt.Errorf("%w: %v", ErrConnectionClosed, err)
 	}
 
 	this.socketMutex.Unlock()
@@ -195,9 +404,86 @@ func (this *ClientConnection) ExchangeWithTimeout(msg *ClientMessage, timeout ti
 	select {
 	case response := <-cb.NotifyChannel:
 		return response, nil
-	case <-time.After(time.Millisecond * timeout):
-		// call timed out
-		return nil, errors.New(fmt.Sprintf("Message exchange timeout. No response received in: %d millis", timeout))
+	case <-this.closed:
+		this.Unregister(cid)
+		return nil, fmt.Errorf("%w while waiting for response to cid: %d", ErrConnectionClosed, cid)
+	case <-ctx.Done():
+		this.Unregister(cid)
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%w. No response received for cid: %d: %w", ErrExchangeTimeout, cid, ctx.Err())
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// A context bounded by the default exchange timeout for operations called without one
+func defaultExchangeContext() (context.Context, context.CancelFunc) {
+
+	return context.WithTimeout(context.Background(), DEFAULT_EXCHANGE_TIMEOUT)
+}
+
+// Write the message, split into fragments when larger than MaxFrameSize. Caller must hold socketMutex
+func (this *ClientConnection) writeMessage(msg *ClientMessage) error {
+
+	for _, frame := range msg.Fragment(this.MaxFrameSize) {
+		frameLength := int(frame.GetFrameLength())
+		n, err := this.socket.Write(frame.Buffer[:frameLength])
+		if nil != err {
+			return err
+		}
+		if n != frameLength {
+			return errors.New(fmt.Sprintf("Fatal socket error: Incomplete write to socket! buffer size=%d, written=%d\r\n", frameLength, n))
+		}
+	}
+
+	atomic.StoreInt64(&this.lastWrite, time.Now().UnixNano())
+
+	return nil
+}
+
+func (this *ClientConnection) LastRead() time.Time {
+
+	return time.Unix(0, atomic.LoadInt64(&this.lastRead))
+}
+
+func (this *ClientConnection) LastWrite() time.Time {
+
+	return time.Unix(0, atomic.LoadInt64(&this.lastWrite))
+}
+
+// Every interval send a ping if nothing has been written for an interval, and close the connection
+// if nothing has been read within timeout. Stops when the connection closes
+func (this *ClientConnection) StartHeartbeat(interval time.Duration, timeout time.Duration) {
+
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.closed:
+				return
+			case now := <-ticker.C:
+				if now.Sub(this.LastRead()) > timeout {
+					this.Logger.Warn("Heartbeat failed, no data received from %v since %v - closing connection", this.Address, this.LastRead())
+					this.Close()
+					return
+				}
+				if now.Sub(this.LastWrite()) >= interval {
+					go this.ping(interval)
+				}
+			}
+		}
+	}()
+}
+
+func (this *ClientConnection) ping(timeout time.Duration) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := SendPingWithContext(ctx, this); err != nil && !this.IsClosed() {
+		this.Logger.Warn("Heartbeat ping to %v failed: %v", this.Address, err)
 	}
 }
 
@@ -205,7 +491,7 @@ func (this *ClientConnection) ExchangeWithTimeout(msg *ClientMessage, timeout ti
 func (this *ClientConnection) Register(correlationId int64) *ResponseCallback {
 
 	responseCallback := ResponseCallback{}
-	responseCallback.NotifyChannel = make(chan *ClientMessage)
+	responseCallback.NotifyChannel = make(chan *ClientMessage, 1)
 
 	this.responsesMutex.Lock()
 
@@ -215,3 +501,45 @@ func (this *ClientConnection) Register(correlationId int64) *ResponseCallback {
 
 	return &responseCallback
 }
+
+// Remove a callback registration, e.g. when the caller has given up waiting for the response
+func (this *ClientConnection) Unregister(correlationId int64) {
+
+	this.responsesMutex.Lock()
+
+	delete(this.responses, correlationId)
+
+	this.responsesMutex.Unlock()
+}
+
+// Event callback registration, events carry the correlation id of the request that added the listener.
+// Register before sending that request so no event is missed.
+func (this *ClientConnection) RegisterListener(correlationId int64) *ResponseCallback {
+
+	responseCallback := &ResponseCallback{}
+	responseCallback.NotifyChannel = make(chan *ClientMessage, LISTENER_EVENT_BUFFER_SIZE)
+	responseCallback.queued = make(chan struct{}, 1)
+	responseCallback.removed = make(chan struct{})
+
+	this.responsesMutex.Lock()
+
+	this.listeners[correlationId] = responseCallback
+
+	this.responsesMutex.Unlock()
+
+	go responseCallback.drain(this.closed)
+
+	return responseCallback
+}
+
+func (this *ClientConnection) UnregisterListener(correlationId int64) {
+
+	this.responsesMutex.Lock()
+
+	if responseCallback, ok := this.listeners[correlationId]; ok {
+		delete(this.listeners, correlationId)
+		close(responseCallback.removed)
+	}
+
+	this.responsesMutex.Unlock()
+}
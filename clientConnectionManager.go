@@ -1,14 +1,163 @@
 package hz
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"sync"
+	"time"
 )
 
-type ClientConnectionManager struct {}
+const (
 
+	CLIENT_TYPE = "GOLANG"
+	SERIALIZATION_VERSION = 1
+	DEFAULT_AUTHENTICATION_TIMEOUT = 30 * time.Second
+)
+
+// Tracks authenticated connections by member address. The zero value is ready to use
+type ClientConnectionManager struct {
+
+	mutex        sync.Mutex
+	connectMutex sync.Mutex
+	connections  map[Address]*ClientConnection
+
+	credentials Credentials
+
+	// The principal assigned by the cluster to the owner connection, presented again when connecting
+	uuid      *string
+	ownerUuid *string
+
+	// Serialization version of the client, SERIALIZATION_VERSION when zero. A member answering with another version is rejected
+	SerializationVersion uint8
+
+	// Time allowed for the authentication response, DEFAULT_AUTHENTICATION_TIMEOUT when zero
+	AuthenticationTimeout time.Duration
+
+	// When HeartbeatInterval is set every connection pings the member when idle, and is closed after HeartbeatTimeout without data received
+	HeartbeatInterval time.Duration
+	HeartbeatTimeout  time.Duration
+
+	// Connections are dialled with TLS when set
+	TLSConfig *TLSConfig
+
+	// Fragment size for outgoing messages, DEFAULT_MAX_FRAME_SIZE when zero
+	MaxFrameSize int
+
+	Logger ILogging
+}
+
+// Connect and authenticate the owner connection, the credentials are reused for any connections opened later on
 func (manager *ClientConnectionManager) GetOrConnect(address Address, hzUser string, hzPassword string) *Promise {
 
+	return manager.GetOrConnectWithCredentials(address, &UsernamePasswordCredentials{Username: hzUser, Password: hzPassword})
+}
+
+func (manager *ClientConnectionManager) GetOrConnectWithCredentials(address Address, credentials Credentials) *Promise {
+
+	manager.mutex.Lock()
+	manager.credentials = credentials
+	manager.mutex.Unlock()
+
+	return manager.connect(address, true)
+}
+
+// Return the live connection to the member address, connecting and authenticating a non owner connection when required
+func (manager *ClientConnectionManager) GetConnection(address Address) (*ClientConnection, error) {
+
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
+
+	return manager.GetConnectionWithContext(ctx, address)
+}
+
+// As GetConnection, giving up on the connect when ctx is done. The connect then completes in the background
+func (manager *ClientConnectionManager) GetConnectionWithContext(ctx context.Context, address Address) (*ClientConnection, error) {
+
+	if connection := manager.liveConnection(address); connection != nil {
+		return connection, nil
+	}
+
+	manager.connectMutex.Lock()
+	defer manager.connectMutex.Unlock()
+
+	if connection := manager.liveConnection(address); connection != nil {
+		return connection, nil
+	}
+
+	promise := manager.connect(address, false)
+
+	select {
+	case obj := <-promise.SuccessChannel:
+		return obj.(*ClientConnection), nil
+	case err := <-promise.FailureChannel:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close every connection opened through the manager
+func (manager *ClientConnectionManager) CloseAll() {
+
+	manager.mutex.Lock()
+	connections := manager.connections
+	manager.connections = nil
+	manager.mutex.Unlock()
+
+	for _, connection := range connections {
+		connection.Close()
+	}
+}
+
+// The uuid and owner uuid assigned by the cluster, nil until the owner connection has authenticated
+func (manager *ClientConnectionManager) Principal() (*string, *string) {
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	return manager.uuid, manager.ownerUuid
+}
+
+func (manager *ClientConnectionManager) liveConnection(address Address) *ClientConnection {
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	connection, ok := manager.connections[address]
+	if ok && !connection.IsClosed() {
+		return connection
+	}
+
+	return nil
+}
+
+func (manager *ClientConnectionManager) register(address Address, connection *ClientConnection) {
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if manager.connections == nil {
+		manager.connections = make(map[Address]*ClientConnection)
+	}
+	manager.connections[address] = connection
+	manager.connections[connection.Address] = connection
+}
+
+func (manager *ClientConnectionManager) connect(address Address, isOwnerConnection bool) *Promise {
+
 	connection := NewClientConnection(address)
+	connection.Logger = manager.Logger
+	if connection.Logger == nil {
+		connection.Logger = noLogging{}
+	}
+	connection.TLSConfig = manager.TLSConfig
+	if manager.MaxFrameSize > 0 {
+		connection.MaxFrameSize = manager.MaxFrameSize
+	}
+
+	manager.mutex.Lock()
+	credentials := manager.credentials
+	manager.mutex.Unlock()
 
 	promise := connection.Connect(address)
 	promise2 := promise.Then(func(obj interface{}) (interface{}, error) {
@@ -20,42 +169,93 @@ func (manager *ClientConnectionManager) GetOrConnect(address Address, hzUser str
 
 	promise3 := promise2.ThenPromise(func(obj interface{}) *Promise {
 		connection := obj.(*ClientConnection)
-		return authenticate(connection, hzUser, hzPassword)
+		return manager.authenticate(connection, credentials, isOwnerConnection)
 	}, func(err error) error{
 		return err
 	})
 
-	return promise3
+	promise4 := promise3.Then(func(obj interface{}) (interface{}, error) {
+		connection := obj.(*ClientConnection)
+		manager.register(address, connection)
+		if manager.HeartbeatInterval > 0 {
+			connection.StartHeartbeat(manager.HeartbeatInterval, manager.HeartbeatTimeout)
+		}
+		return connection, nil
+	}, func(err error) error{
+		return err
+	})
+
+	return promise4
 }
 
-func authenticate(connection *ClientConnection,hzUser string, hzPassword string) *Promise {
+// Authenticate over the framed read path, the read loop is started here
+func (manager *ClientConnectionManager) authenticate(connection *ClientConnection, credentials Credentials, isOwnerConnection bool) *Promise {
 
 	result := new(Promise)
 
 	result.SuccessChannel = make(chan interface{}, 1)
 	result.FailureChannel = make(chan error, 1)
 
-	request := EncodeRequest(hzUser, hzPassword, nil, nil, true, "GOLANG", 1)
-	request.SetCorrelationId(1)
-	request.SetPartitionId(-1)
-	request.SetFlags(BEGIN_END_FLAG)
-
-	connection.socket.Write(request.Buffer)
+	timeout := manager.AuthenticationTimeout
+	if timeout == 0 {
+		timeout = DEFAULT_AUTHENTICATION_TIMEOUT
+	}
 
-	rBuffer := make([]byte, 1024)
-	readBytes, _ := connection.socket.Read(rBuffer)
-	response := CreateForDecode(rBuffer[:readBytes])
+	serializationVersion := manager.SerializationVersion
+	if serializationVersion == 0 {
+		serializationVersion = SERIALIZATION_VERSION
+	}
 
 	go func() {
+		uuid, ownerUuid := manager.Principal()
+
+		request := credentials.encodeAuthenticationRequest(uuid, ownerUuid, isOwnerConnection, CLIENT_TYPE, serializationVersion)
+		request.SetCorrelationId(connection.NextCorrelationId())
+		request.SetPartitionId(-1)
+		request.SetFlags(BEGIN_END_FLAG)
+
+		connection.InitReadLoop()
+
+		response, err := connection.ExchangeWithTimeout(request, timeout)
+		if err == nil {
+			err = checkResponse(response, RESPONSE_AUTHENTICATION)
+		}
+		if err != nil {
+			connection.Close()
+			result.FailureChannel <- err
+			return
+		}
+
 		authResponse := DecodeResponse(response)
-		if authResponse.Status == 0 {
+		if err := authenticationStatusError(authResponse.Status, connection.Address); err != nil {
+			connection.Close()
+			result.FailureChannel <- err
+			return
+		}
+
+		if authResponse.SerializationVersion != serializationVersion {
+			connection.Close()
+			result.FailureChannel <- fmt.Errorf("%w: %s, client version: %d, member version: %d", ErrSerializationVersionMismatch, connection.Address.String(), serializationVersion, authResponse.SerializationVersion)
+			return
+		}
+
+		if authResponse.Address != nil {
 			connection.Address.Host = authResponse.Address.Host
 			connection.Address.Port = authResponse.Address.Port
-			result.SuccessChannel <- connection
-		} else {
-			result.FailureChannel <- errors.New("Connection is NOT authenticated" + connection.Address.String())
 		}
+		connection.SerializationVersion = authResponse.SerializationVersion
+
+		if isOwnerConnection {
+			manager.mutex.Lock()
+			manager.uuid = authResponse.Uuid
+			manager.ownerUuid = authResponse.OwnerUuid
+			manager.mutex.Unlock()
+		}
+
+		connection.Logger.Trace("Authenticated %v, uuid: %v, serialization version: %d", connection.Address, authResponse.Uuid, authResponse.SerializationVersion)
+
+		result.SuccessChannel <- connection
 	}()
 
 	return result
-}
\ No newline at end of file
+}
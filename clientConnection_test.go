@@ -0,0 +1,241 @@
+package hz
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+	"time"
+	"testing"
+)
+
+func largeOffer(t *testing.T, correlationId uint64, item string) *ClientMessage {
+
+	t.Helper()
+
+	request := EncodeQueueOfferRequest("jobs", Data(append([]byte{0, 0, 0, 0, 0xff, 0xff, 0xff, 0xf5}, item...)), 0)
+	request.SetCorrelationId(correlationId)
+	request.SetPartitionId(7)
+	request.SetFlags(BEGIN_END_FLAG)
+
+	return request
+}
+
+func frameBytes(message *ClientMessage) []byte {
+
+	return message.Buffer[:message.GetFrameLength()]
+}
+
+func TestFragment(t *testing.T) {
+
+	request := largeOffer(t, 3, strings.Repeat("a", 200))
+	fragments := request.Fragment(64)
+
+	if len(fragments) < 2 {
+		t.Fatalf("got %d fragments", len(fragments))
+	}
+
+	for i, fragment := range fragments {
+		if fragment.GetFrameLength() > 64 {
+			t.Fatalf("fragment %d: frame length %d", i, fragment.GetFrameLength())
+		}
+		if fragment.GetCorrelationId() != 3 || fragment.GetPartitionId() != 7 || fragment.GetMessageType() != request.GetMessageType() {
+			t.Fatalf("fragment %d: header not copied", i)
+		}
+
+		want := uint8(0)
+		if i == 0 {
+			want |= BEGIN_FLAG
+		}
+		if i == len(fragments)-1 {
+			want |= END_FLAG
+		}
+		if fragment.HasFlags(BEGIN_END_FLAG) != want {
+			t.Fatalf("fragment %d: flags 0x%02x, want 0x%02x", i, fragment.GetFlags(), want)
+		}
+	}
+
+	if small := largeOffer(t, 4, "a"); len(small.Fragment(DEFAULT_MAX_FRAME_SIZE)) != 1 {
+		t.Fatal("message within the frame size was fragmented")
+	}
+}
+
+func TestAssembleInterleavedFragments(t *testing.T) {
+
+	connection := NewClientConnection(Address{Host: "127.0.0.1", Port: 5701})
+	connection.Logger = testLogger{t}
+
+	first := largeOffer(t, 1, strings.Repeat("a", 150))
+	second := largeOffer(t, 2, strings.Repeat("b", 250))
+	want := map[int64][]byte{1: bytes.Clone(frameBytes(first)), 2: bytes.Clone(frameBytes(second))}
+
+	firstFragments := first.Fragment(48)
+	secondFragments := second.Fragment(48)
+
+	var frames []*ClientMessage
+	for i := 0; i < len(firstFragments) || i < len(secondFragments); i++ {
+		if i < len(secondFragments) {
+			frames = append(frames, secondFragments[i])
+		}
+		if i < len(firstFragments) {
+			frames = append(frames, firstFragments[i])
+		}
+	}
+
+	got := make(map[int64][]byte)
+	for _, frame := range frames {
+		if message := connection.assemble(receivedMessage(t, frame)); message != nil {
+			got[message.GetCorrelationId()] = frameBytes(message)
+		}
+	}
+
+	for correlationId, message := range want {
+		if !bytes.Equal(got[correlationId], message) {
+			t.Fatalf("correlation id %d: reassembled message differs from the original", correlationId)
+		}
+	}
+	if len(connection.fragments) != 0 {
+		t.Fatalf("%d partial messages left", len(connection.fragments))
+	}
+
+	if message := connection.assemble(receivedMessage(t, firstFragments[1])); message != nil {
+		t.Fatal("fragment without BEGIN frame assembled")
+	}
+}
+
+func TestMaxFrameSize(t *testing.T) {
+
+	server := startStub(t, func(request *ClientMessage) []*ClientMessage {
+		if request.GetMessageType() == CLIENT_QUEUE_OFFER {
+			return []*ClientMessage{boolResponse(true)}
+		}
+		return nil
+	})
+
+	config := NewClientConfig()
+	config.Addresses = []Address{server.address}
+	config.Logger = testLogger{t}
+
+	config.MaxFrameSize = HEADER_SIZE
+	if _, err := NewHazelcastClient(config); err == nil {
+		t.Fatal("MaxFrameSize of HEADER_SIZE accepted")
+	}
+
+	config.MaxFrameSize = 64
+	client, err := NewHazelcastClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Shutdown()
+
+	connection, err := client.OwnerConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if connection.MaxFrameSize != 64 {
+		t.Fatalf("connection MaxFrameSize: got %d, want 64", connection.MaxFrameSize)
+	}
+
+	// The stub reassembles the fragments before answering
+	response, err := client.InvocationService().Invoke(largeOffer(t, connection.NextCorrelationId(), strings.Repeat("c", 300)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.GetMessageType() != RESPONSE_BOOLEAN {
+		t.Fatalf("response type: 0x%04x", response.GetMessageType())
+	}
+}
+
+func TestListenerEventOrder(t *testing.T) {
+
+	connection := NewClientConnection(Address{Host: "127.0.0.1", Port: 5701})
+	connection.Logger = testLogger{t}
+
+	cb := connection.RegisterListener(5)
+	defer connection.UnregisterListener(5)
+
+	// More events than the channel buffers, the read loop must not block while nobody receives
+	const events = 3 * LISTENER_EVENT_BUFFER_SIZE
+	for i := 0; i < events; i++ {
+		event := intResponse(int32(i))
+		event.SetFlags(BEGIN_END_FLAG | LISTENER_FLAG)
+		event.SetCorrelationId(5)
+		connection.dispatch(receivedMessage(t, event))
+	}
+
+	for i := 0; i < events; i++ {
+		select {
+		case event := <-cb.NotifyChannel:
+			if got := event.readInt(); got != int32(i) {
+				t.Fatalf("event %d: got %d", i, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not delivered", i)
+		}
+	}
+}
+
+// An authenticated connection to the stub, pinging every interval
+func connectWithHeartbeat(t *testing.T, address Address, interval time.Duration, timeout time.Duration) *ClientConnection {
+
+	manager := &ClientConnectionManager{Logger: testLogger{t}, HeartbeatInterval: interval, HeartbeatTimeout: timeout}
+	t.Cleanup(manager.CloseAll)
+
+	promise := manager.GetOrConnect(address, "dev", "dev-pass")
+
+	select {
+	case connection := <-promise.SuccessChannel:
+		return connection.(*ClientConnection)
+	case err := <-promise.FailureChannel:
+		t.Fatal(err)
+	}
+
+	return nil
+}
+
+func TestHeartbeatPing(t *testing.T) {
+
+	var pings int32
+	server := startStub(t, func(request *ClientMessage) []*ClientMessage {
+		if request.GetMessageType() == CLIENT_PING {
+			atomic.AddInt32(&pings, 1)
+		}
+		return nil
+	})
+
+	connection := connectWithHeartbeat(t, server.address, 20 * time.Millisecond, time.Minute)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&pings) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pings sent while idle: %d", atomic.LoadInt32(&pings))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if connection.IsClosed() {
+		t.Fatal("answered connection closed")
+	}
+}
+
+func TestHeartbeatTimeout(t *testing.T) {
+
+	// Pings are read but never answered
+	server := startStub(t, func(request *ClientMessage) []*ClientMessage {
+		if request.GetMessageType() == CLIENT_PING {
+			return []*ClientMessage{}
+		}
+		return nil
+	})
+
+	connection := connectWithHeartbeat(t, server.address, 20 * time.Millisecond, 200 * time.Millisecond)
+
+	select {
+	case <-connection.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection without response not closed")
+	}
+
+	if since := time.Since(connection.LastRead()); since < 200 * time.Millisecond {
+		t.Fatalf("closed %v after the last read", since)
+	}
+}
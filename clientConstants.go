@@ -25,8 +25,82 @@ const (
 	CLIENT_ADDDISTRIBUTEDOBJECTLISTENER = 0x000d
 	CLIENT_REMOVEDISTRIBUTEDOBJECTLISTENER = 0x000e
 	CLIENT_PING = 0x000f
+	CLIENT_ADDPARTITIONLISTENER = 0x0012
 	CLIENT_QUEUE_PUT = 0x0302
 	CLIENT_QUEUE_POLL = 0x0305
 	CLIENT_QUEUE_ADD_LISTENER = 0x0311
 	CLIENT_QUEUE_CLEAR = 0x030F
+	CLIENT_QUEUE_OFFER = 0x0301
+	CLIENT_QUEUE_SIZE = 0x0303
+	CLIENT_QUEUE_REMOVE = 0x0304
+	CLIENT_QUEUE_TAKE = 0x0306
+	CLIENT_QUEUE_PEEK = 0x0307
+	CLIENT_QUEUE_ITERATOR = 0x0308
+	CLIENT_QUEUE_DRAIN_TO = 0x0309
+	CLIENT_QUEUE_DRAIN_TO_MAX_SIZE = 0x030a
+	CLIENT_QUEUE_CONTAINS = 0x030b
+	CLIENT_QUEUE_CONTAINS_ALL = 0x030c
+	CLIENT_QUEUE_COMPARE_AND_REMOVE_ALL = 0x030d
+	CLIENT_QUEUE_COMPARE_AND_RETAIN_ALL = 0x030e
+	CLIENT_QUEUE_ADD_ALL = 0x0310
+	CLIENT_QUEUE_REMOVE_LISTENER = 0x0312
+	CLIENT_QUEUE_REMAINING_CAPACITY = 0x0313
+	CLIENT_QUEUE_IS_EMPTY = 0x0314
+	CLIENT_MAP_PUT = 0x0101
+	CLIENT_MAP_GET = 0x0102
+	CLIENT_MAP_REMOVE = 0x0103
+	CLIENT_MAP_REPLACE = 0x0104
+	CLIENT_MAP_REPLACE_IF_SAME = 0x0105
+	CLIENT_MAP_CONTAINS_KEY = 0x0109
+	CLIENT_MAP_CONTAINS_VALUE = 0x010a
+	CLIENT_MAP_REMOVE_IF_SAME = 0x010b
+	CLIENT_MAP_DELETE = 0x010c
+	CLIENT_MAP_PUT_IF_ABSENT = 0x0111
+	CLIENT_MAP_SET = 0x0112
+	CLIENT_MAP_SIZE = 0x012e
+	CLIENT_MAP_IS_EMPTY = 0x012f
+	CLIENT_MAP_CLEAR = 0x0131
+	CLIENT_MAP_FLUSH = 0x010d
+	CLIENT_MAP_PUT_TRANSIENT = 0x0110
+	CLIENT_MAP_EVICT = 0x0122
+	CLIENT_MAP_EVICT_ALL = 0x0123
+	// 0x0149 to 0x014d are only known to Hazelcast 3.11 and later
+	CLIENT_MAP_SET_TTL = 0x0149
+	CLIENT_MAP_PUT_WITH_MAX_IDLE = 0x014a
+	CLIENT_MAP_PUT_TRANSIENT_WITH_MAX_IDLE = 0x014b
+	CLIENT_MAP_PUT_IF_ABSENT_WITH_MAX_IDLE = 0x014c
+	CLIENT_MAP_SET_WITH_MAX_IDLE = 0x014d
+	CLIENT_MAP_KEY_SET = 0x0126
+	CLIENT_MAP_GET_ALL = 0x0127
+	CLIENT_MAP_VALUES = 0x0128
+	CLIENT_MAP_ENTRY_SET = 0x0129
+	CLIENT_MAP_PUT_ALL = 0x0130
+	CLIENT_MAP_KEY_SET_WITH_PREDICATE = 0x012a
+	CLIENT_MAP_VALUES_WITH_PREDICATE = 0x012b
+	CLIENT_MAP_ENTRIES_WITH_PREDICATE = 0x012c
+	CLIENT_MAP_KEY_SET_WITH_PAGING_PREDICATE = 0x0138
+	CLIENT_MAP_VALUES_WITH_PAGING_PREDICATE = 0x0139
+	CLIENT_MAP_ENTRIES_WITH_PAGING_PREDICATE = 0x013a
+)
+
+const (
+	RESPONSE_VOID = 0x0064
+	RESPONSE_BOOLEAN = 0x0065
+	RESPONSE_INTEGER = 0x0066
+	RESPONSE_LONG = 0x0067
+	RESPONSE_STRING = 0x0068
+	RESPONSE_DATA = 0x0069
+	RESPONSE_LIST_DATA = 0x006a
+	RESPONSE_AUTHENTICATION = 0x006b
+	RESPONSE_PARTITIONS = 0x006c
+	RESPONSE_EXCEPTION = 0x006d
+	RESPONSE_LIST_ENTRY = 0x0075
+)
+
+const (
+	EVENT_MEMBER = 0x00c8
+	EVENT_MEMBERLIST = 0x00c9
+	EVENT_MEMBERATTRIBUTECHANGE = 0x00ca
+	EVENT_ITEM = 0x00cc
+	EVENT_PARTITIONS = 0x00d9
 )
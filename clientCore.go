@@ -17,6 +17,19 @@ func (address Address) String() string {
 	return fmt.Sprintf("Address: host=%s, port=%d", address.Host, address.Port)
 }
 
+type Member struct {
+
+	Address    Address
+	Uuid       string
+	LiteMember bool
+	Attributes map[string]string
+}
+
+func (member Member) String() string {
+
+	return fmt.Sprintf("Member: %s:%d, uuid=%s, lite=%t", member.Address.Host, member.Address.Port, member.Uuid, member.LiteMember)
+}
+
 type Promise struct {
 
 	SuccessChannel chan interface{}
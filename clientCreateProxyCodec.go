@@ -1,5 +1,7 @@
 package hz
 
+import "context"
+
 func EncodeProxyCreateRequest(connection *ClientConnection, name string, serviceName string) *ClientMessage {
 
 	payloadSize := calculatePayloadSize(name, serviceName, connection.Address.Host)
@@ -45,33 +47,60 @@ func calculatePayloadSize(name string, serviceName string, host string) int {
 	return dataSize
 }
 
-func SendProxyDestroyRequest(connection *ClientConnection, name string, serviceName string) {
+func SendProxyDestroyRequest(connection *ClientConnection, name string, serviceName string) error {
+
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
+
+	return SendProxyDestroyRequestWithContext(ctx, connection, name, serviceName)
+}
+
+func SendProxyDestroyRequestWithContext(ctx context.Context, connection *ClientConnection, name string, serviceName string) error {
 
 	request := EncodeProxyDestroyRequest(name, serviceName)
 
 	request.SetCorrelationId(connection.NextCorrelationId())
 	request.SetFlags(BEGIN_END_FLAG)
 
-	response, _ := connection.Exchange(request)
-	if response.GetMessageType() != 0x0064 {
-		connection.Logger.Error("Unexpected response to proxy destroy ! Type: 0x%04x", response.GetMessageType())
-	} else {
-		connection.Logger.Trace("Proxy destroyed without error: %s on %s", name, serviceName)
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if err := checkResponse(response, RESPONSE_VOID); err != nil {
+		return err
 	}
+
+	connection.Logger.Trace("Proxy destroyed without error: %s on %s", name, serviceName)
+
+	return nil
+}
+
+func SendProxyRequest(connection *ClientConnection, name string, serviceName string) error {
+
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
+
+	return SendProxyRequestWithContext(ctx, connection, name, serviceName)
 }
 
-func SendProxyRequest(connection *ClientConnection, name string, serviceName string) {
+func SendProxyRequestWithContext(ctx context.Context, connection *ClientConnection, name string, serviceName string) error {
 
 	request := EncodeProxyCreateRequest(connection, name, serviceName)
 
 	request.SetCorrelationId(connection.NextCorrelationId())
 	request.SetFlags(BEGIN_END_FLAG)
 
-	response, _ := connection.Exchange(request)
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		return err
+	}
 
-	if response.GetMessageType() != 0x0064 {
-		connection.Logger.Error("Unexpected response to proxy request ! Type: 0x%04x", response.GetMessageType())
-	} else {
-		connection.Logger.Trace("Proxy configured for: %s on %s", name, serviceName)
+	if err := checkResponse(response, RESPONSE_VOID); err != nil {
+		return err
 	}
+
+	connection.Logger.Trace("Proxy configured for: %s on %s", name, serviceName)
+
+	return nil
 }
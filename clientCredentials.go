@@ -0,0 +1,91 @@
+package hz
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+
+	AUTHENTICATED = 0
+	CREDENTIALS_FAILED = 1
+	SERIALIZATION_VERSION_MISMATCH = 2
+	NOT_ALLOWED_IN_CLUSTER = 3
+)
+
+var (
+
+	ErrCredentialsFailed = errors.New("Authentication failed, invalid credentials")
+	ErrSerializationVersionMismatch = errors.New("Authentication failed, serialization version mismatch")
+	ErrNotAllowedInCluster = errors.New("Authentication failed, client not allowed in cluster")
+)
+
+// Credentials presented when authenticating a connection. The set is closed, UsernamePasswordCredentials and CustomCredentials are the
+// only implementations. Credentials for a member side credentials class are given as CustomCredentials
+type Credentials interface {
+
+	encodeAuthenticationRequest(uuid *string, ownerUuid *string, isOwnerConnection bool, clientType string, serializationVersion uint8) *ClientMessage
+}
+
+// Group name and password, sent with CLIENT_AUTHENTICATION
+type UsernamePasswordCredentials struct {
+
+	Username string
+	Password string
+}
+
+func (this *UsernamePasswordCredentials) encodeAuthenticationRequest(uuid *string, ownerUuid *string, isOwnerConnection bool, clientType string, serializationVersion uint8) *ClientMessage {
+
+	return EncodeRequest(this.Username, this.Password, uuid, ownerUuid, isOwnerConnection, clientType, serializationVersion)
+}
+
+// Credentials for a member side credentials class, sent with CLIENT_AUTHENTICATIONCUSTOM
+type CustomCredentials struct {
+
+	// Serialized with the client SerializationService when connecting, usually a Portable or IdentifiedDataSerializable mirroring the member side class
+	Value interface{}
+
+	// Credentials already serialized, used instead of Value when set
+	Data Data
+}
+
+// Credentials with Value serialized to Data
+func (this *CustomCredentials) serialize(service *SerializationService) (*CustomCredentials, error) {
+
+	if this.Data != nil {
+		return this, nil
+	}
+
+	data, err := service.ToData(this.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, errors.New("CustomCredentials requires a Value or Data")
+	}
+
+	return &CustomCredentials{Value: this.Value, Data: data}, nil
+}
+
+func (this *CustomCredentials) encodeAuthenticationRequest(uuid *string, ownerUuid *string, isOwnerConnection bool, clientType string, serializationVersion uint8) *ClientMessage {
+
+	return EncodeCustomRequest(this.Data, uuid, ownerUuid, isOwnerConnection, clientType, serializationVersion)
+}
+
+// Map an authentication status to an error, nil when authenticated
+func authenticationStatusError(status byte, address Address) error {
+
+	switch status {
+	case AUTHENTICATED:
+		return nil
+	case CREDENTIALS_FAILED:
+		return fmt.Errorf("%w: %s", ErrCredentialsFailed, address.String())
+	case SERIALIZATION_VERSION_MISMATCH:
+		return fmt.Errorf("%w: %s", ErrSerializationVersionMismatch, address.String())
+	case NOT_ALLOWED_IN_CLUSTER:
+		return fmt.Errorf("%w: %s", ErrNotAllowedInCluster, address.String())
+	}
+
+	return errors.New(fmt.Sprintf("Connection is NOT authenticated, status: %d, %s", status, address.String()))
+}
@@ -0,0 +1,57 @@
+package hz
+
+import (
+	"errors"
+	"fmt"
+)
+
+func DecodeError(message *ClientMessage) *HazelcastError {
+
+	hzError := new(HazelcastError)
+	hzError.ErrorCode = message.readInt()
+	hzError.ClassName = *message.readString()
+	if !message.readBool() {
+		hzError.Message = message.readString()
+	}
+
+	elements := message.readInt()
+	hzError.StackTrace = make([]StackTraceElement, elements)
+	for i := int32(0); i < elements; i++ {
+		hzError.StackTrace[i] = decodeStackTraceElement(message)
+	}
+
+	causeErrorCode := message.readInt()
+	if !message.readBool() {
+		hzError.Cause = new(HazelcastError)
+		hzError.Cause.ErrorCode = causeErrorCode
+		hzError.Cause.ClassName = *message.readString()
+	}
+
+	return hzError
+}
+
+func decodeStackTraceElement(message *ClientMessage) StackTraceElement {
+
+	element := StackTraceElement{}
+	element.DeclaringClass = *message.readString()
+	element.MethodName = *message.readString()
+	if !message.readBool() {
+		element.FileName = message.readString()
+	}
+	element.LineNumber = message.readInt()
+
+	return element
+}
+
+// Verify the response is of the expected type, decoding any server exception into a *HazelcastError
+func checkResponse(response *ClientMessage, expectedType uint16) error {
+
+	switch response.GetMessageType() {
+	case expectedType:
+		return nil
+	case RESPONSE_EXCEPTION:
+		return DecodeError(response)
+	}
+
+	return errors.New(fmt.Sprintf("Unexpected response type: 0x%04x, expected: 0x%04x", response.GetMessageType(), expectedType))
+}
@@ -0,0 +1,125 @@
+package hz
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// A frame assembled byte by byte in the layout a member writes, the payload appended with the helpers below
+func fixtureFrame(messageType uint16, payload []byte) *ClientMessage {
+
+	header := make([]byte, HEADER_SIZE)
+	binary.LittleEndian.PutUint32(header[FRAME_LENGTH_FIELD_OFFSET:], uint32(HEADER_SIZE+len(payload)))
+	header[VERSION_FIELD_OFFSET] = 1
+	header[FLAGS_FIELD_OFFSET] = BEGIN_END_FLAG
+	binary.LittleEndian.PutUint16(header[TYPE_FIELD_OFFSET:], messageType)
+	binary.LittleEndian.PutUint64(header[CORRELATION_ID_FIELD_OFFSET:], 9)
+	binary.LittleEndian.PutUint32(header[PARTITION_ID_FIELD_OFFSET:], 0xffffffff)
+	binary.LittleEndian.PutUint16(header[DATA_OFFSET_FIELD_OFFSET:], HEADER_SIZE)
+
+	return CreateForDecode(append(header, payload...))
+}
+
+func fixtureInt(buffer []byte, value int32) []byte {
+
+	return binary.LittleEndian.AppendUint32(buffer, uint32(value))
+}
+
+func fixtureBool(buffer []byte, value bool) []byte {
+
+	if value {
+		return append(buffer, 1)
+	}
+
+	return append(buffer, 0)
+}
+
+func fixtureString(buffer []byte, value string) []byte {
+
+	return append(fixtureInt(buffer, int32(len(value))), value...)
+}
+
+func TestDecodeError(t *testing.T) {
+
+	payload := fixtureInt(nil, ERROR_ILLEGAL_STATE)
+	payload = fixtureString(payload, "java.lang.IllegalStateException")
+	payload = fixtureBool(payload, false)
+	payload = fixtureString(payload, "Queue is full")
+	// stack trace
+	payload = fixtureInt(payload, 2)
+	payload = fixtureString(payload, "com.hazelcast.collection.impl.queue.QueueContainer")
+	payload = fixtureString(payload, "offer")
+	payload = fixtureBool(payload, false)
+	payload = fixtureString(payload, "QueueContainer.java")
+	payload = fixtureInt(payload, 215)
+	payload = fixtureString(payload, "sun.reflect.GeneratedMethodAccessor1")
+	payload = fixtureString(payload, "invoke")
+	payload = fixtureBool(payload, true)
+	payload = fixtureInt(payload, -1)
+	// cause
+	payload = fixtureInt(payload, ERROR_IO)
+	payload = fixtureBool(payload, false)
+	payload = fixtureString(payload, "java.io.IOException")
+
+	err := checkResponse(fixtureFrame(RESPONSE_EXCEPTION, payload), RESPONSE_BOOLEAN)
+
+	var hzError *HazelcastError
+	if !errors.As(err, &hzError) {
+		t.Fatalf("got %T: %v", err, err)
+	}
+
+	if hzError.ErrorCode != ERROR_ILLEGAL_STATE || hzError.ClassName != "java.lang.IllegalStateException" {
+		t.Fatalf("got %d %s", hzError.ErrorCode, hzError.ClassName)
+	}
+	if got := hzError.Error(); got != "java.lang.IllegalStateException: Queue is full" {
+		t.Fatalf("message: got %q", got)
+	}
+
+	fileName := "QueueContainer.java"
+	wantStackTrace := []StackTraceElement{
+		{DeclaringClass: "com.hazelcast.collection.impl.queue.QueueContainer", MethodName: "offer", FileName: &fileName, LineNumber: 215},
+		{DeclaringClass: "sun.reflect.GeneratedMethodAccessor1", MethodName: "invoke", LineNumber: -1},
+	}
+	if !reflect.DeepEqual(hzError.StackTrace, wantStackTrace) {
+		t.Fatalf("stack trace: got %v", hzError.StackTrace)
+	}
+	if got := hzError.StackTrace[1].String(); got != "sun.reflect.GeneratedMethodAccessor1.invoke(Unknown Source)" {
+		t.Fatalf("stack trace element: got %q", got)
+	}
+
+	if hzError.Cause == nil || hzError.Cause.ErrorCode != ERROR_IO || hzError.Cause.ClassName != "java.io.IOException" {
+		t.Fatalf("cause: got %v", hzError.Cause)
+	}
+	if !errors.Is(err, ErrIllegalState) || !errors.Is(err, &HazelcastError{ErrorCode: ERROR_IO}) {
+		t.Fatal("error or its cause not matched")
+	}
+}
+
+func TestHazelcastErrorIs(t *testing.T) {
+
+	custom := &HazelcastError{ErrorCode: ERROR_UNDEFINED, ClassName: "com.example.OrderRejectedException"}
+
+	tests := []struct {
+		name   string
+		target *HazelcastError
+		want   bool
+	}{
+		{"another error code", &HazelcastError{ErrorCode: ERROR_QUERY, ClassName: "com.hazelcast.query.QueryException"}, false},
+		{"undefined with the same class name", &HazelcastError{ErrorCode: ERROR_UNDEFINED, ClassName: "com.example.OrderRejectedException"}, true},
+		{"undefined with another class name", &HazelcastError{ErrorCode: ERROR_UNDEFINED, ClassName: "com.example.PaymentException"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := errors.Is(custom, test.target); got != test.want {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	if !errors.Is(&HazelcastError{ErrorCode: ERROR_QUERY, ClassName: "com.hazelcast.query.impl.QueryExceptionSubclass"}, ErrQuery) {
+		t.Fatal("defined error codes match regardless of class name")
+	}
+}
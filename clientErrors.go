@@ -0,0 +1,174 @@
+package hz
+
+import "fmt"
+
+/*
+	Hazelcast protocol error codes as carried in the 0x006d error response
+ */
+
+const (
+	ERROR_UNDEFINED = 0
+	ERROR_ARRAY_INDEX_OUT_OF_BOUNDS = 1
+	ERROR_ARRAY_STORE = 2
+	ERROR_AUTHENTICATION = 3
+	ERROR_CACHE = 4
+	ERROR_CACHE_LOADER = 5
+	ERROR_CACHE_NOT_EXISTS = 6
+	ERROR_CACHE_WRITER = 7
+	ERROR_CALLER_NOT_MEMBER = 8
+	ERROR_CANCELLATION = 9
+	ERROR_CLASS_CAST = 10
+	ERROR_CLASS_NOT_FOUND = 11
+	ERROR_CONCURRENT_MODIFICATION = 12
+	ERROR_CONFIG_MISMATCH = 13
+	ERROR_CONFIGURATION = 14
+	ERROR_DISTRIBUTED_OBJECT_DESTROYED = 15
+	ERROR_DUPLICATE_INSTANCE_NAME = 16
+	ERROR_EOF = 17
+	ERROR_ENTRY_PROCESSOR = 18
+	ERROR_EXECUTION = 19
+	ERROR_HAZELCAST = 20
+	ERROR_HAZELCAST_INSTANCE_NOT_ACTIVE = 21
+	ERROR_HAZELCAST_OVERLOAD = 22
+	ERROR_HAZELCAST_SERIALIZATION = 23
+	ERROR_IO = 24
+	ERROR_ILLEGAL_ARGUMENT = 25
+	ERROR_ILLEGAL_ACCESS_EXCEPTION = 26
+	ERROR_ILLEGAL_ACCESS_ERROR = 27
+	ERROR_ILLEGAL_MONITOR_STATE = 28
+	ERROR_ILLEGAL_STATE = 29
+	ERROR_ILLEGAL_THREAD_STATE = 30
+	ERROR_INDEX_OUT_OF_BOUNDS = 31
+	ERROR_INTERRUPTED = 32
+	ERROR_INVALID_ADDRESS = 33
+	ERROR_INVALID_CONFIGURATION = 34
+	ERROR_MEMBER_LEFT = 35
+	ERROR_NEGATIVE_ARRAY_SIZE = 36
+	ERROR_NO_SUCH_ELEMENT = 37
+	ERROR_NOT_SERIALIZABLE = 38
+	ERROR_NULL_POINTER = 39
+	ERROR_OPERATION_TIMEOUT = 40
+	ERROR_PARTITION_MIGRATING = 41
+	ERROR_QUERY = 42
+	ERROR_QUERY_RESULT_SIZE_EXCEEDED = 43
+	ERROR_QUORUM = 44
+	ERROR_REACHED_MAX_SIZE = 45
+	ERROR_REJECTED_EXECUTION = 46
+	ERROR_REMOTE_MAP_REDUCE = 47
+	ERROR_RESPONSE_ALREADY_SENT = 48
+	ERROR_RETRYABLE_HAZELCAST = 49
+	ERROR_RETRYABLE_IO = 50
+	ERROR_RUNTIME = 51
+	ERROR_SECURITY = 52
+	ERROR_SOCKET = 53
+	ERROR_STALE_SEQUENCE = 54
+	ERROR_TARGET_DISCONNECTED = 55
+	ERROR_TARGET_NOT_MEMBER = 56
+	ERROR_TIMEOUT = 57
+	ERROR_TOPIC_OVERLOAD = 58
+	ERROR_TOPOLOGY_CHANGED = 59
+	ERROR_TRANSACTION = 60
+	ERROR_TRANSACTION_NOT_ACTIVE = 61
+	ERROR_TRANSACTION_TIMED_OUT = 62
+	ERROR_URI_SYNTAX = 63
+	ERROR_UTF_DATA_FORMAT = 64
+	ERROR_UNSUPPORTED_OPERATION = 65
+	ERROR_WRONG_TARGET = 66
+	ERROR_XA = 67
+	ERROR_ACCESS_CONTROL = 68
+	ERROR_LOGIN = 69
+	ERROR_UNSUPPORTED_CALLBACK = 70
+	ERROR_NO_DATA_MEMBER = 71
+	ERROR_REPLICATED_MAP_CANT_BE_CREATED = 72
+	ERROR_MAX_MESSAGE_SIZE_EXCEEDED = 73
+	ERROR_WAN_REPLICATION_QUEUE_FULL = 74
+	ERROR_ASSERTION_ERROR = 75
+	ERROR_OUT_OF_MEMORY_ERROR = 76
+	ERROR_STACK_OVERFLOW_ERROR = 77
+	ERROR_NATIVE_OUT_OF_MEMORY_ERROR = 78
+	ERROR_SERVICE_NOT_FOUND = 79
+)
+
+/*
+	Sentinels for use with errors.Is, matched on error code
+ */
+
+var (
+	ErrAuthentication = &HazelcastError{ErrorCode: ERROR_AUTHENTICATION, ClassName: "com.hazelcast.client.AuthenticationException"}
+	ErrCallerNotMember = &HazelcastError{ErrorCode: ERROR_CALLER_NOT_MEMBER, ClassName: "com.hazelcast.spi.exception.CallerNotMemberException"}
+	ErrDistributedObjectDestroyed = &HazelcastError{ErrorCode: ERROR_DISTRIBUTED_OBJECT_DESTROYED, ClassName: "com.hazelcast.spi.exception.DistributedObjectDestroyedException"}
+	ErrHazelcastInstanceNotActive = &HazelcastError{ErrorCode: ERROR_HAZELCAST_INSTANCE_NOT_ACTIVE, ClassName: "com.hazelcast.core.HazelcastInstanceNotActiveException"}
+	ErrHazelcastOverload = &HazelcastError{ErrorCode: ERROR_HAZELCAST_OVERLOAD, ClassName: "com.hazelcast.core.HazelcastOverloadException"}
+	ErrHazelcastSerialization = &HazelcastError{ErrorCode: ERROR_HAZELCAST_SERIALIZATION, ClassName: "com.hazelcast.nio.serialization.HazelcastSerializationException"}
+	ErrIllegalArgument = &HazelcastError{ErrorCode: ERROR_ILLEGAL_ARGUMENT, ClassName: "java.lang.IllegalArgumentException"}
+	ErrIllegalState = &HazelcastError{ErrorCode: ERROR_ILLEGAL_STATE, ClassName: "java.lang.IllegalStateException"}
+	ErrMemberLeft = &HazelcastError{ErrorCode: ERROR_MEMBER_LEFT, ClassName: "com.hazelcast.core.MemberLeftException"}
+	ErrNullPointer = &HazelcastError{ErrorCode: ERROR_NULL_POINTER, ClassName: "java.lang.NullPointerException"}
+	ErrOperationTimeout = &HazelcastError{ErrorCode: ERROR_OPERATION_TIMEOUT, ClassName: "com.hazelcast.core.OperationTimeoutException"}
+	ErrPartitionMigrating = &HazelcastError{ErrorCode: ERROR_PARTITION_MIGRATING, ClassName: "com.hazelcast.spi.exception.PartitionMigratingException"}
+	ErrQuery = &HazelcastError{ErrorCode: ERROR_QUERY, ClassName: "com.hazelcast.query.QueryException"}
+	ErrRetryableHazelcast = &HazelcastError{ErrorCode: ERROR_RETRYABLE_HAZELCAST, ClassName: "com.hazelcast.spi.exception.RetryableHazelcastException"}
+	ErrRetryableIO = &HazelcastError{ErrorCode: ERROR_RETRYABLE_IO, ClassName: "com.hazelcast.spi.exception.RetryableIOException"}
+	ErrTargetDisconnected = &HazelcastError{ErrorCode: ERROR_TARGET_DISCONNECTED, ClassName: "com.hazelcast.spi.exception.TargetDisconnectedException"}
+	ErrTargetNotMember = &HazelcastError{ErrorCode: ERROR_TARGET_NOT_MEMBER, ClassName: "com.hazelcast.spi.exception.TargetNotMemberException"}
+	ErrUnsupportedOperation = &HazelcastError{ErrorCode: ERROR_UNSUPPORTED_OPERATION, ClassName: "java.lang.UnsupportedOperationException"}
+	ErrWanReplicationQueueFull = &HazelcastError{ErrorCode: ERROR_WAN_REPLICATION_QUEUE_FULL, ClassName: "com.hazelcast.wan.WANReplicationQueueFullException"}
+	ErrWrongTarget = &HazelcastError{ErrorCode: ERROR_WRONG_TARGET, ClassName: "com.hazelcast.spi.exception.WrongTargetException"}
+)
+
+type StackTraceElement struct {
+
+	DeclaringClass string
+	MethodName     string
+	FileName       *string
+	LineNumber     int32
+}
+
+func (element StackTraceElement) String() string {
+
+	fileName := "Unknown Source"
+	if element.FileName != nil {
+		fileName = fmt.Sprintf("%s:%d", *element.FileName, element.LineNumber)
+	}
+
+	return fmt.Sprintf("%s.%s(%s)", element.DeclaringClass, element.MethodName, fileName)
+}
+
+// A server side exception decoded from an error response
+type HazelcastError struct {
+
+	ErrorCode  int32
+	ClassName  string
+	Message    *string
+	StackTrace []StackTraceElement
+	Cause      *HazelcastError
+}
+
+func (this *HazelcastError) Error() string {
+
+	if this.Message == nil {
+		return this.ClassName
+	}
+
+	return fmt.Sprintf("%s: %s", this.ClassName, *this.Message)
+}
+
+// Errors are equivalent when their protocol error codes match, undefined errors also need the same class name
+func (this *HazelcastError) Is(target error) bool {
+
+	other, ok := target.(*HazelcastError)
+	if !ok || other.ErrorCode != this.ErrorCode {
+		return false
+	}
+
+	return this.ErrorCode != ERROR_UNDEFINED || other.ClassName == this.ClassName
+}
+
+func (this *HazelcastError) Unwrap() error {
+
+	if this.Cause == nil {
+		return nil
+	}
+
+	return this.Cause
+}
@@ -0,0 +1,46 @@
+package hz
+
+import (
+	"context"
+)
+
+// The pending result of an asynchronous operation. The bool result has the meaning of the matching synchronous operation,
+// e.g. whether a previous value was present
+type Future[T any] struct {
+
+	done  chan struct{}
+	value T
+	ok    bool
+	err   error
+}
+
+// Run the operation on its own goroutine
+func newFuture[T any](operation func() (T, bool, error)) *Future[T] {
+
+	future := &Future[T]{done: make(chan struct{})}
+
+	go func() {
+		future.value, future.ok, future.err = operation()
+		close(future.done)
+	}()
+
+	return future
+}
+
+// Closed once the result is available
+func (this *Future[T]) Done() <-chan struct{} {
+
+	return this.done
+}
+
+// Wait for the result. When ctx is done first its error is returned and the operation carries on
+func (this *Future[T]) Get(ctx context.Context) (T, bool, error) {
+
+	select {
+	case <-this.done:
+		return this.value, this.ok, this.err
+	case <-ctx.Done():
+		var zero T
+		return zero, false, ctx.Err()
+	}
+}
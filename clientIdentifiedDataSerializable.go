@@ -0,0 +1,102 @@
+package hz
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+
+	DATA_SERIALIZABLE_IDENTIFIED_FLAG = 1 << 0
+	DATA_SERIALIZABLE_VERSIONED_FLAG = 1 << 1
+)
+
+// Shared with java IdentifiedDataSerializable classes through matching factory and class ids
+type IdentifiedDataSerializable interface {
+
+	FactoryID() int32
+	ClassID() int32
+	WriteData(output *ObjectDataOutput) error
+	ReadData(input *ObjectDataInput) error
+}
+
+// Creates empty instances to read into, nil for an unknown class id
+type DataSerializableFactory interface {
+
+	Create(classId int32) IdentifiedDataSerializable
+}
+
+// Adapts a function to a DataSerializableFactory
+type DataSerializableFactoryFunc func(classId int32) IdentifiedDataSerializable
+
+func (f DataSerializableFactoryFunc) Create(classId int32) IdentifiedDataSerializable {
+
+	return f(classId)
+}
+
+/*
+Payload: a header byte with the identified flag set, the factory id and class id, then the fields as written by WriteData.
+Only identified content can be read, a plain java DataSerializable carries a class name instead of ids.
+*/
+type dataSerializableSerializer struct {
+
+	factories map[int32]DataSerializableFactory
+}
+
+func (dataSerializableSerializer) TypeId() int32 { return TYPE_DATA_SERIALIZABLE }
+
+func (this dataSerializableSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	serializable := value.(IdentifiedDataSerializable)
+
+	output.WriteUInt8(DATA_SERIALIZABLE_IDENTIFIED_FLAG)
+	output.WriteInt32(serializable.FactoryID())
+	output.WriteInt32(serializable.ClassID())
+
+	return serializable.WriteData(output)
+}
+
+func (this dataSerializableSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	header := input.ReadUInt8()
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	if header&DATA_SERIALIZABLE_IDENTIFIED_FLAG == 0 {
+		return nil, errors.New("Only IdentifiedDataSerializable content can be deserialized")
+	}
+
+	factoryId := input.ReadInt32()
+	classId := input.ReadInt32()
+
+	if header&DATA_SERIALIZABLE_VERSIONED_FLAG != 0 {
+		// Enterprise versioned content, skip the factory and class versions
+		input.ReadUInt8()
+		input.ReadUInt8()
+	}
+
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	factory, ok := this.factories[factoryId]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("No DataSerializableFactory registered for factory id: %d", factoryId))
+	}
+
+	serializable := factory.Create(classId)
+	if serializable == nil {
+		return nil, errors.New(fmt.Sprintf("DataSerializableFactory %d cannot create class id: %d", factoryId, classId))
+	}
+
+	if err := serializable.ReadData(input); err != nil {
+		return nil, err
+	}
+
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	return serializable, nil
+}
@@ -0,0 +1,122 @@
+package hz
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type employee struct {
+
+	Id   int32
+	Name string
+}
+
+func (*employee) FactoryID() int32 { return 1 }
+func (*employee) ClassID() int32   { return 2 }
+
+func (this *employee) WriteData(output *ObjectDataOutput) error {
+
+	output.WriteInt32(this.Id)
+	output.WriteUTF(this.Name)
+
+	return nil
+}
+
+func (this *employee) ReadData(input *ObjectDataInput) error {
+
+	this.Id = input.ReadInt32()
+	this.Name = input.ReadUTF()
+
+	return input.Error()
+}
+
+func newEmployeeService(t *testing.T) *SerializationService {
+
+	config := NewSerializationConfig()
+	config.AddDataSerializableFactory(1, DataSerializableFactoryFunc(func(classId int32) IdentifiedDataSerializable {
+		if classId == 2 {
+			return &employee{}
+		}
+		return nil
+	}))
+
+	service, err := NewSerializationService(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return service
+}
+
+// As written by the java client for new Employee(7, "Ann")
+var employeeData = Data{
+	0, 0, 0, 0, // partition hash
+	0xff, 0xff, 0xff, 0xfe, // TYPE_DATA_SERIALIZABLE
+	0x01, // identified
+	0, 0, 0, 1, // factory id
+	0, 0, 0, 2, // class id
+	0, 0, 0, 7,
+	0, 0, 0, 3, 'A', 'n', 'n',
+}
+
+func TestIdentifiedDataSerializableBytes(t *testing.T) {
+
+	service := newEmployeeService(t)
+
+	data, err := service.ToData(&employee{Id: 7, Name: "Ann"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, employeeData) {
+		t.Fatalf("got % x\nwant % x", []byte(data), []byte(employeeData))
+	}
+
+	object, err := service.ToObject(employeeData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(object, &employee{Id: 7, Name: "Ann"}) {
+		t.Fatalf("got %#v", object)
+	}
+
+	// Enterprise members add the factory and class versions after the ids
+	versioned := append(append(Data{}, employeeData[:DATA_OFFSET]...), DATA_SERIALIZABLE_IDENTIFIED_FLAG|DATA_SERIALIZABLE_VERSIONED_FLAG, 0, 0, 0, 1, 0, 0, 0, 2, 1, 1)
+	versioned = append(versioned, employeeData[DATA_OFFSET+9:]...)
+	if object, err := service.ToObject(versioned); err != nil || !reflect.DeepEqual(object, &employee{Id: 7, Name: "Ann"}) {
+		t.Fatalf("versioned: got %#v, %v", object, err)
+	}
+}
+
+func TestIdentifiedDataSerializableErrors(t *testing.T) {
+
+	service := newEmployeeService(t)
+
+	withHeader := func(header ...byte) Data {
+		data := append(Data{}, employeeData[:DATA_OFFSET]...)
+		data = append(data, header...)
+		return append(data, employeeData[DATA_OFFSET+9:]...)
+	}
+
+	tests := []struct {
+		name string
+		data Data
+		want string
+	}{
+		{"unknown factory", withHeader(1, 0, 0, 0, 9, 0, 0, 0, 2), "factory id: 9"},
+		{"unknown class", withHeader(1, 0, 0, 0, 1, 0, 0, 0, 5), "class id: 5"},
+		{"not identified", withHeader(0, 0, 0, 0, 1, 0, 0, 0, 2), "IdentifiedDataSerializable"},
+		{"truncated", employeeData[:len(employeeData)-2], ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, err := service.ToObject(test.data)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("got %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package hz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+
+	DEFAULT_INVOCATION_TIMEOUT = 2 * time.Minute
+	DEFAULT_INVOCATION_RETRY_PAUSE = 1 * time.Second
+	DEFAULT_INVOCATION_MAX_RETRY_PAUSE = 30 * time.Second
+	DEFAULT_INVOCATION_BACKOFF_MULTIPLIER = 2.0
+)
+
+var (
+
+	ErrInvocationTimeout = errors.New("Invocation timeout")
+)
+
+type InvocationConfig struct {
+
+	// Total time allowed for an invocation, including all retries
+	InvocationTimeout time.Duration
+
+	// Pause before the first retry, multiplied by BackoffMultiplier on each subsequent retry up to MaxRetryPause
+	RetryPause        time.Duration
+	MaxRetryPause     time.Duration
+	BackoffMultiplier float64
+
+	// Also retry messages not flagged as retryable. Such operations may then be executed more than once!
+	RedoOperation bool
+
+	// Send partition bound messages directly to the member owning the partition
+	SmartRouting bool
+}
+
+func NewInvocationConfig() *InvocationConfig {
+
+	config := new(InvocationConfig)
+	config.InvocationTimeout = DEFAULT_INVOCATION_TIMEOUT
+	config.RetryPause = DEFAULT_INVOCATION_RETRY_PAUSE
+	config.MaxRetryPause = DEFAULT_INVOCATION_MAX_RETRY_PAUSE
+	config.BackoffMultiplier = DEFAULT_INVOCATION_BACKOFF_MULTIPLIER
+
+	return config
+}
+
+// Sends requests over the owner ClientConnection, or with smart routing to the partition owner, retrying those that fail with a retryable error
+type InvocationService struct {
+
+	config            *InvocationConfig
+	connectionMutex   sync.RWMutex
+	connection        *ClientConnection
+	connectionManager *ClientConnectionManager
+	partitionService  *PartitionService
+	listenerService   *ListenerService
+
+	Logger ILogging
+}
+
+// The connection manager opens connections to partition owners and is only required for smart routing
+func NewInvocationService(connectionManager *ClientConnectionManager, connection *ClientConnection, config *InvocationConfig) *InvocationService {
+
+	service := new(InvocationService)
+	service.connectionManager = connectionManager
+	service.connection = connection
+	service.config = config
+	service.Logger = connection.Logger
+	service.partitionService = newPartitionService(service)
+	service.listenerService = newListenerService(service)
+
+	return service
+}
+
+// Replace the owner connection, e.g. after a reconnect
+func (this *InvocationService) SetOwnerConnection(connection *ClientConnection) {
+
+	this.connectionMutex.Lock()
+	this.connection = connection
+	this.connectionMutex.Unlock()
+}
+
+// The owner connection, ErrConnectionClosed while it is down
+func (this *InvocationService) ownerConnection() (*ClientConnection, error) {
+
+	this.connectionMutex.RLock()
+	connection := this.connection
+	this.connectionMutex.RUnlock()
+
+	if connection.IsClosed() {
+		return nil, fmt.Errorf("%w: %s", ErrConnectionClosed, connection.Address.String())
+	}
+
+	return connection, nil
+}
+
+// Listeners registered here are added again to the owner connection after a reconnect
+func (this *InvocationService) ListenerService() *ListenerService {
+
+	return this.listenerService
+}
+
+// The partition table used for smart routing, smart routing falls back to the owner connection until it has been started
+func (this *InvocationService) PartitionService() *PartitionService {
+
+	return this.partitionService
+}
+
+func (this *InvocationService) Invoke(request *ClientMessage) (*ClientMessage, error) {
+
+	return this.InvokeWithContext(context.Background(), request)
+}
+
+// Send the request, retrying until a response arrives, a non retryable error occurs, the invocation timeout expires or the context is done.
+// A server exception is returned as a *HazelcastError, otherwise the caller is responsible for checking the response type.
+func (this *InvocationService) InvokeWithContext(ctx context.Context, request *ClientMessage) (*ClientMessage, error) {
+
+	return this.InvokeBlockingWithContext(ctx, request, 0)
+}
+
+// As InvokeWithContext for operations that wait on the cluster, such as a queue take or poll. The invocation timeout is extended by blockFor,
+// a negative blockFor leaves the invocation bounded only by the context.
+func (this *InvocationService) InvokeBlockingWithContext(ctx context.Context, request *ClientMessage, blockFor time.Duration) (*ClientMessage, error) {
+
+	if blockFor >= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, this.config.InvocationTimeout + blockFor)
+		defer cancel()
+	}
+
+	pause := this.config.RetryPause
+
+	for attempt := 1; ; attempt++ {
+
+		connection, err := this.connectionFor(ctx, request)
+		if err == nil {
+			var response *ClientMessage
+			response, err = this.InvokeOnConnectionWithContext(ctx, connection, request)
+			if err == nil {
+				return response, nil
+			}
+		}
+
+		if !this.isRetryable(request, err) {
+			return nil, err
+		}
+
+		this.Logger.Warn("Retrying invocation of type: 0x%04x in %v, attempt: %d, error: %v", request.GetMessageType(), pause, attempt, err)
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("%w after %d attempts, last error: %w", ErrInvocationTimeout, attempt, err)
+			}
+			return nil, ctx.Err()
+		case <-time.After(pause):
+		}
+
+		pause = this.nextPause(pause)
+	}
+}
+
+// A single attempt over the given connection, without retry
+func (this *InvocationService) InvokeOnConnectionWithContext(ctx context.Context, connection *ClientConnection, request *ClientMessage) (*ClientMessage, error) {
+
+	request.SetCorrelationId(connection.NextCorrelationId())
+	request.SetFlags(BEGIN_END_FLAG)
+
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.GetMessageType() == RESPONSE_EXCEPTION {
+		return nil, DecodeError(response)
+	}
+
+	return response, nil
+}
+
+// The connection to the partition owner with smart routing, otherwise or when that member cannot be reached the owner connection
+func (this *InvocationService) connectionFor(ctx context.Context, request *ClientMessage) (*ClientConnection, error) {
+
+	partitionId := request.GetPartitionId()
+
+	if this.config.SmartRouting && this.connectionManager != nil && partitionId >= 0 {
+		if address := this.partitionService.GetPartitionOwner(partitionId); address != nil {
+			connection, err := this.connectionManager.GetConnectionWithContext(ctx, *address)
+			if err == nil {
+				return connection, nil
+			}
+			// The member will forward the operation to the partition owner
+			this.Logger.Warn("Failed to connect to owner of partition: %d, %v - using owner connection", partitionId, err)
+		}
+	}
+
+	return this.ownerConnection()
+}
+
+// Only errors that leave the cluster state unchanged or indicate a lost connection are worth another attempt
+func (this *InvocationService) isRetryable(request *ClientMessage, err error) bool {
+
+	if !request.IsRetryable() && !this.config.RedoOperation {
+		return false
+	}
+
+	return errors.Is(err, ErrConnectionClosed) ||
+		errors.Is(err, ErrHazelcastInstanceNotActive) ||
+		errors.Is(err, ErrTargetNotMember) ||
+		errors.Is(err, ErrTargetDisconnected) ||
+		errors.Is(err, ErrCallerNotMember) ||
+		errors.Is(err, ErrMemberLeft) ||
+		errors.Is(err, ErrWrongTarget) ||
+		errors.Is(err, ErrPartitionMigrating) ||
+		errors.Is(err, ErrRetryableHazelcast) ||
+		errors.Is(err, ErrRetryableIO)
+}
+
+func (this *InvocationService) nextPause(pause time.Duration) time.Duration {
+
+	next := time.Duration(float64(pause) * this.config.BackoffMultiplier)
+	if next > this.config.MaxRetryPause {
+		next = this.config.MaxRetryPause
+	}
+
+	return next
+}
@@ -0,0 +1,200 @@
+package hz
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A stub client whose queue size requests are answered by respond, called with the attempt number from 1
+func newRetryStubClient(t *testing.T, invocationTimeout time.Duration, respond func(attempt int32) *ClientMessage) (*HazelcastClient, *int32) {
+
+	attempts := new(int32)
+
+	client, _ := newConfiguredStubClient(t, func(request *ClientMessage) []*ClientMessage {
+		if request.GetMessageType() == CLIENT_QUEUE_SIZE {
+			return []*ClientMessage{respond(atomic.AddInt32(attempts, 1))}
+		}
+		return nil
+	}, func(config *ClientConfig) {
+		config.InvocationConfig.InvocationTimeout = invocationTimeout
+		config.InvocationConfig.RetryPause = 10 * time.Millisecond
+		config.InvocationConfig.MaxRetryPause = 20 * time.Millisecond
+	})
+
+	return client, attempts
+}
+
+func retryableSizeRequest(retryable bool) *ClientMessage {
+
+	request := EncodeQueueSizeRequest("jobs")
+	request.SetPartitionId(-1)
+	request.SetIsRetryable(retryable)
+
+	return request
+}
+
+func TestInvocationRetry(t *testing.T) {
+
+	client, attempts := newRetryStubClient(t, time.Minute, func(attempt int32) *ClientMessage {
+		switch attempt {
+		case 1:
+			return errorResponse(ERROR_PARTITION_MIGRATING, "com.hazelcast.spi.exception.PartitionMigratingException")
+		case 2:
+			return errorResponse(ERROR_HAZELCAST_INSTANCE_NOT_ACTIVE, "com.hazelcast.core.HazelcastInstanceNotActiveException")
+		}
+		return intResponse(3)
+	})
+
+	response, err := client.InvocationService().Invoke(retryableSizeRequest(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := DecodeQueueIntegerResponse(response); got != 3 {
+		t.Fatalf("got %d, want 3", got)
+	}
+	if got := atomic.LoadInt32(attempts); got != 3 {
+		t.Fatalf("attempts: got %d, want 3", got)
+	}
+}
+
+func TestInvocationTimeout(t *testing.T) {
+
+	client, attempts := newRetryStubClient(t, 200 * time.Millisecond, func(attempt int32) *ClientMessage {
+		return errorResponse(ERROR_RETRYABLE_HAZELCAST, "com.hazelcast.spi.exception.RetryableHazelcastException")
+	})
+
+	start := time.Now()
+	_, err := client.InvocationService().InvokeWithContext(context.Background(), retryableSizeRequest(true))
+
+	if !errors.Is(err, ErrInvocationTimeout) || !errors.Is(err, ErrRetryableHazelcast) {
+		t.Fatalf("got %v, want the invocation timeout with the last error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5 * time.Second {
+		t.Fatalf("gave up after %v", elapsed)
+	}
+	if got := atomic.LoadInt32(attempts); got < 2 {
+		t.Fatalf("attempts: got %d, want retries before the timeout", got)
+	}
+}
+
+func TestInvocationNotRetried(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		retryable bool
+		response  *ClientMessage
+		want      error
+	}{
+		{"non retryable error", true, errorResponse(ERROR_ILLEGAL_STATE, "java.lang.IllegalStateException"), ErrIllegalState},
+		{"non retryable request", false, errorResponse(ERROR_PARTITION_MIGRATING, "com.hazelcast.spi.exception.PartitionMigratingException"), ErrPartitionMigrating},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			client, attempts := newRetryStubClient(t, time.Minute, func(attempt int32) *ClientMessage {
+				return test.response
+			})
+
+			_, err := client.InvocationService().Invoke(retryableSizeRequest(test.retryable))
+
+			var hzError *HazelcastError
+			if !errors.As(err, &hzError) || !errors.Is(err, test.want) {
+				t.Fatalf("got %v, want %v", err, test.want)
+			}
+			if got := atomic.LoadInt32(attempts); got != 1 {
+				t.Fatalf("attempts: got %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestSmartRouting(t *testing.T) {
+
+	var ownerRequests, memberRequests int32
+	var ownerAddress, memberAddress Address
+
+	member := startStub(t, func(request *ClientMessage) []*ClientMessage {
+		if request.GetMessageType() == CLIENT_QUEUE_SIZE {
+			atomic.AddInt32(&memberRequests, 1)
+			return []*ClientMessage{intResponse(2)}
+		}
+		return nil
+	})
+	memberAddress = member.address
+
+	client, owner := newConfiguredStubClient(t, func(request *ClientMessage) []*ClientMessage {
+		switch request.GetMessageType() {
+		case CLIENT_GETPARTITIONS:
+			return []*ClientMessage{partitionTableResponse([]MemberPartitions{
+				{Address: ownerAddress, PartitionIds: []int32{0, 2}},
+				{Address: memberAddress, PartitionIds: []int32{1, 3}},
+			})}
+		case CLIENT_QUEUE_SIZE:
+			atomic.AddInt32(&ownerRequests, 1)
+			return []*ClientMessage{intResponse(1)}
+		}
+		return nil
+	}, func(config *ClientConfig) {
+		config.InvocationConfig.SmartRouting = true
+	})
+	ownerAddress = owner.address
+
+	// The table loaded while connecting had no owner address yet
+	if err := client.PartitionService().Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for partitionId, want := range []int32{1, 2, 1, 2} {
+		request := EncodeQueueSizeRequest("jobs")
+		request.SetPartitionId(int32(partitionId))
+
+		response, err := client.InvocationService().Invoke(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := DecodeQueueIntegerResponse(response); got != want {
+			t.Fatalf("partition %d: answered by member %d, want %d", partitionId, got, want)
+		}
+	}
+
+	if owner, member := atomic.LoadInt32(&ownerRequests), atomic.LoadInt32(&memberRequests); owner != 2 || member != 2 {
+		t.Fatalf("requests on the owner: %d, on the member: %d", owner, member)
+	}
+}
+
+func TestSmartRoutingConnectHonoursContext(t *testing.T) {
+
+	// Accepts connections but never answers the authentication
+	silent := startStub(t, func(request *ClientMessage) []*ClientMessage {
+		return []*ClientMessage{}
+	})
+
+	client, _ := newConfiguredStubClient(t, func(request *ClientMessage) []*ClientMessage {
+		if request.GetMessageType() == CLIENT_GETPARTITIONS {
+			return []*ClientMessage{partitionTableResponse([]MemberPartitions{{Address: silent.address, PartitionIds: []int32{0}}})}
+		}
+		return nil
+	}, func(config *ClientConfig) {
+		config.InvocationConfig.SmartRouting = true
+		// The abandoned connect to the silent member only fails once the test has ended
+		config.Logger = noLogging{}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200 * time.Millisecond)
+	defer cancel()
+
+	request := EncodeQueueSizeRequest("jobs")
+	request.SetPartitionId(0)
+
+	start := time.Now()
+	if _, err := client.InvocationService().InvokeWithContext(ctx, request); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5 * time.Second {
+		t.Fatalf("returned after %v", elapsed)
+	}
+}
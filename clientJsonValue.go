@@ -0,0 +1,55 @@
+package hz
+
+import (
+	"encoding/json"
+)
+
+// A JSON document stored as the java HazelcastJsonValue, queryable on the cluster and shown as JSON by Management Center
+type HazelcastJsonValue struct {
+
+	value string
+}
+
+// Wrap a JSON string, it is not validated
+func NewHazelcastJsonValue(jsonString string) HazelcastJsonValue {
+
+	return HazelcastJsonValue{value: jsonString}
+}
+
+// Marshal a value with encoding/json
+func NewHazelcastJsonValueFromObject(v interface{}) (HazelcastJsonValue, error) {
+
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return HazelcastJsonValue{}, err
+	}
+
+	return HazelcastJsonValue{value: string(bytes)}, nil
+}
+
+// Unmarshal the JSON into v with encoding/json
+func (this HazelcastJsonValue) Unmarshal(v interface{}) error {
+
+	return json.Unmarshal([]byte(this.value), v)
+}
+
+func (this HazelcastJsonValue) String() string {
+
+	return this.value
+}
+
+// Payload: the JSON string as java writeUTF
+type jsonValueSerializer struct{}
+
+func (jsonValueSerializer) TypeId() int32 { return TYPE_JSON }
+
+func (jsonValueSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteUTF(value.(HazelcastJsonValue).value)
+	return nil
+}
+
+func (jsonValueSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return HazelcastJsonValue{value: input.ReadUTF()}, input.Error()
+}
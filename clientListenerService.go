@@ -0,0 +1,204 @@
+package hz
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+type listenerRegistration struct {
+
+	request      func() *ClientMessage
+	responseType uint16
+
+	// Handed to the caller, events from every connection the listener is registered on are forwarded here
+	callback *ResponseCallback
+
+	connection     *ClientConnection
+	correlationId  int64
+	registrationId string
+
+	stop chan struct{}
+}
+
+// Keeps listeners registered on the owner connection, adding them again after the client reconnects
+type ListenerService struct {
+
+	invocationService *InvocationService
+
+	mutex          sync.Mutex
+	registrations  map[string]*listenerRegistration
+	nextListenerId int
+
+	Logger ILogging
+}
+
+func newListenerService(invocationService *InvocationService) *ListenerService {
+
+	service := new(ListenerService)
+	service.invocationService = invocationService
+	service.registrations = make(map[string]*listenerRegistration)
+	service.Logger = invocationService.Logger
+
+	return service
+}
+
+// Send the add listener request built by the request func on the owner connection. The returned callback receives the events,
+// and keeps doing so after a reconnect, until DeregisterListener is called with the returned id
+func (this *ListenerService) RegisterListener(ctx context.Context, request func() *ClientMessage, responseType uint16) (string, *ResponseCallback, error) {
+
+	registration := new(listenerRegistration)
+	registration.request = request
+	registration.responseType = responseType
+	registration.callback = &ResponseCallback{NotifyChannel: make(chan *ClientMessage, LISTENER_EVENT_BUFFER_SIZE)}
+	registration.stop = make(chan struct{})
+
+	if err := this.register(ctx, registration); err != nil {
+		return "", nil, err
+	}
+
+	this.mutex.Lock()
+	this.nextListenerId++
+	id := strconv.Itoa(this.nextListenerId)
+	this.registrations[id] = registration
+	this.mutex.Unlock()
+
+	return id, registration.callback, nil
+}
+
+// Stop delivering events for the listener. When encodeRemove is given it is used to deregister the listener on the cluster
+func (this *ListenerService) DeregisterListener(ctx context.Context, id string, encodeRemove func(registrationId string) *ClientMessage) error {
+
+	this.mutex.Lock()
+	registration, ok := this.registrations[id]
+	delete(this.registrations, id)
+	this.mutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	this.mutex.Lock()
+	connection := registration.connection
+	correlationId := registration.correlationId
+	registrationId := registration.registrationId
+	this.mutex.Unlock()
+
+	close(registration.stop)
+	connection.UnregisterListener(correlationId)
+
+	if encodeRemove == nil {
+		return nil
+	}
+
+	response, err := this.invocationService.InvokeWithContext(ctx, encodeRemove(registrationId))
+	if err != nil {
+		return err
+	}
+
+	return checkResponse(response, RESPONSE_BOOLEAN)
+}
+
+// Closed once the listener is deregistered, already closed for an unknown id
+func (this *ListenerService) Done(id string) <-chan struct{} {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	registration, ok := this.registrations[id]
+	if !ok {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+
+	return registration.stop
+}
+
+// The id assigned by the cluster to the current registration of the listener
+func (this *ListenerService) RegistrationId(id string) (string, bool) {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	registration, ok := this.registrations[id]
+	if !ok {
+		return "", false
+	}
+
+	return registration.registrationId, true
+}
+
+// Add every listener to the current owner connection, called once the client has reconnected
+func (this *ListenerService) reregister(ctx context.Context) {
+
+	this.mutex.Lock()
+	registrations := make([]*listenerRegistration, 0, len(this.registrations))
+	for _, registration := range this.registrations {
+		registrations = append(registrations, registration)
+	}
+	this.mutex.Unlock()
+
+	for _, registration := range registrations {
+		if err := this.register(ctx, registration); err != nil {
+			this.Logger.Error("Failed to register listener again after reconnect: %v", err)
+		}
+	}
+}
+
+func (this *ListenerService) register(ctx context.Context, registration *listenerRegistration) error {
+
+	connection, err := this.invocationService.ownerConnection()
+	if err != nil {
+		return err
+	}
+
+	request := registration.request()
+	request.SetCorrelationId(connection.NextCorrelationId())
+	request.SetPartitionId(-1)
+	request.SetFlags(BEGIN_END_FLAG)
+
+	cid := request.GetCorrelationId()
+	cb := connection.RegisterListener(cid)
+
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err == nil {
+		err = checkResponse(response, registration.responseType)
+	}
+	if err != nil {
+		connection.UnregisterListener(cid)
+		return err
+	}
+
+	registrationId := ""
+	if registration.responseType == RESPONSE_STRING {
+		registrationId = *response.readString()
+	}
+
+	this.mutex.Lock()
+	registration.connection = connection
+	registration.correlationId = cid
+	registration.registrationId = registrationId
+	this.mutex.Unlock()
+
+	this.Logger.Trace("Listener registered, type: 0x%04x, registrationId: %s", request.GetMessageType(), registrationId)
+
+	go func() {
+		for {
+			select {
+			case <-registration.stop:
+				return
+			case <-connection.closed:
+				return
+			case event := <-cb.NotifyChannel:
+				select {
+				case registration.callback.NotifyChannel <- event:
+				case <-registration.stop:
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,863 @@
+package hz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+
+	MAP_SERVICE_NAME = "hz:impl:mapService"
+)
+
+// A distributed map, keys and values are serialized with the client SerializationService.
+// Keys are compared by their serialized form on the cluster, not with Go equality
+type Map[K any, V any] struct {
+
+	name   string
+	client *HazelcastClient
+}
+
+// A key and its value, as returned by the bulk operations
+type Entry[K any, V any] struct {
+
+	Key   K
+	Value V
+}
+
+// Get a map proxy, creating the map on the cluster the first time the name is used by this client.
+// A function rather than a method of HazelcastClient as Go methods cannot have type parameters
+func GetMap[K any, V any](ctx context.Context, client *HazelcastClient, name string) (*Map[K, V], error) {
+
+	if err := client.createProxy(ctx, name, MAP_SERVICE_NAME); err != nil {
+		return nil, err
+	}
+
+	m := new(Map[K, V])
+	m.name = name
+	m.client = client
+
+	return m, nil
+}
+
+func (this *Map[K, V]) Name() string {
+
+	return this.name
+}
+
+// Map key to value, returning the previous value. False when the key was not mapped
+func (this *Map[K, V]) Put(ctx context.Context, key K, value V) (V, bool, error) {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapPutRequest(this.name, keyData, valueData, MAP_THREAD_ID, MAP_DEFAULT_TTL))
+}
+
+// Map key to value without returning the previous value, cheaper than Put
+func (this *Map[K, V]) Set(ctx context.Context, key K, value V) error {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.invoke(ctx, keyData, EncodeMapSetRequest(this.name, keyData, valueData, MAP_THREAD_ID, MAP_DEFAULT_TTL), RESPONSE_VOID)
+
+	return err
+}
+
+// Map key to value unless already mapped. Returns the current value and true when the key was already mapped and nothing was put
+func (this *Map[K, V]) PutIfAbsent(ctx context.Context, key K, value V) (V, bool, error) {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapPutIfAbsentRequest(this.name, keyData, valueData, MAP_THREAD_ID, MAP_DEFAULT_TTL))
+}
+
+// The value mapped to key, false when not mapped
+func (this *Map[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+
+	keyData, err := this.toData(key, "key")
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapGetRequest(this.name, keyData, MAP_THREAD_ID))
+}
+
+// Remove the mapping for key, returning the removed value. False when the key was not mapped
+func (this *Map[K, V]) Remove(ctx context.Context, key K) (V, bool, error) {
+
+	keyData, err := this.toData(key, "key")
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapRemoveRequest(this.name, keyData, MAP_THREAD_ID))
+}
+
+// Remove the mapping for key only when it is mapped to value
+func (this *Map[K, V]) RemoveIfSame(ctx context.Context, key K, value V) (bool, error) {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, keyData, EncodeMapRemoveIfSameRequest(this.name, keyData, valueData, MAP_THREAD_ID))
+}
+
+// Remove the mapping for key without returning the removed value, cheaper than Remove
+func (this *Map[K, V]) Delete(ctx context.Context, key K) error {
+
+	keyData, err := this.toData(key, "key")
+	if err != nil {
+		return err
+	}
+
+	_, err = this.invoke(ctx, keyData, EncodeMapDeleteRequest(this.name, keyData, MAP_THREAD_ID), RESPONSE_VOID)
+
+	return err
+}
+
+func (this *Map[K, V]) ContainsKey(ctx context.Context, key K) (bool, error) {
+
+	keyData, err := this.toData(key, "key")
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, keyData, EncodeMapContainsKeyRequest(this.name, keyData, MAP_THREAD_ID))
+}
+
+// Values are compared by their serialized form, every partition is searched
+func (this *Map[K, V]) ContainsValue(ctx context.Context, value V) (bool, error) {
+
+	valueData, err := this.toData(value, "value")
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, nil, EncodeMapContainsValueRequest(this.name, valueData))
+}
+
+// Replace the value only when key is mapped, returning the previous value. False when the key was not mapped and nothing was replaced
+func (this *Map[K, V]) Replace(ctx context.Context, key K, value V) (V, bool, error) {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapReplaceRequest(this.name, keyData, valueData, MAP_THREAD_ID))
+}
+
+// Replace the value only when key is mapped to oldValue
+func (this *Map[K, V]) ReplaceIfSame(ctx context.Context, key K, oldValue V, newValue V) (bool, error) {
+
+	keyData, oldData, err := this.toKeyValueData(key, oldValue)
+	if err != nil {
+		return false, err
+	}
+
+	newData, err := this.toData(newValue, "value")
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, keyData, EncodeMapReplaceIfSameRequest(this.name, keyData, oldData, newData, MAP_THREAD_ID))
+}
+
+func (this *Map[K, V]) Size(ctx context.Context) (int32, error) {
+
+	response, err := this.invoke(ctx, nil, EncodeMapSizeRequest(this.name), RESPONSE_INTEGER)
+	if err != nil {
+		return 0, err
+	}
+
+	return DecodeMapIntegerResponse(response), nil
+}
+
+func (this *Map[K, V]) IsEmpty(ctx context.Context) (bool, error) {
+
+	return this.invokeBool(ctx, nil, EncodeMapIsEmptyRequest(this.name))
+}
+
+// Remove all entries
+func (this *Map[K, V]) Clear(ctx context.Context) error {
+
+	_, err := this.invoke(ctx, nil, EncodeMapClearRequest(this.name), RESPONSE_VOID)
+
+	return err
+}
+
+/*
+	Bulk operations. The cluster handles getAll and putAll one partition at a time, so keys are grouped by partition
+	and the requests sent in parallel, each to the owner of its partition
+ */
+
+// The entries for the keys that are mapped, in no particular order
+func (this *Map[K, V]) GetAll(ctx context.Context, keys []K) ([]Entry[K, V], error) {
+
+	partitionKeys := make(map[int32][]Data)
+	for _, key := range keys {
+		keyData, err := this.toData(key, "key")
+		if err != nil {
+			return nil, err
+		}
+		partitionId := this.client.PartitionService().GetDataPartitionId(keyData)
+		partitionKeys[partitionId] = append(partitionKeys[partitionId], keyData)
+	}
+
+	requests := make(map[int32]*ClientMessage, len(partitionKeys))
+	for partitionId, keyList := range partitionKeys {
+		requests[partitionId] = EncodeMapGetAllRequest(this.name, keyList)
+	}
+
+	responses, err := this.invokeOnPartitions(ctx, requests, RESPONSE_LIST_ENTRY)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry[K, V]
+	for _, response := range responses {
+		partitionEntries, err := this.toEntries(DecodeMapListEntryResponse(response))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, partitionEntries...)
+	}
+
+	return entries, nil
+}
+
+// Map every key to its value. Partitions are updated independently, on error some may have been updated
+func (this *Map[K, V]) PutAll(ctx context.Context, entries []Entry[K, V]) error {
+
+	partitionEntries := make(map[int32][]DataEntry)
+	for _, entry := range entries {
+		keyData, valueData, err := this.toKeyValueData(entry.Key, entry.Value)
+		if err != nil {
+			return err
+		}
+		partitionId := this.client.PartitionService().GetDataPartitionId(keyData)
+		partitionEntries[partitionId] = append(partitionEntries[partitionId], DataEntry{Key: keyData, Value: valueData})
+	}
+
+	requests := make(map[int32]*ClientMessage, len(partitionEntries))
+	for partitionId, entryList := range partitionEntries {
+		requests[partitionId] = EncodeMapPutAllRequest(this.name, entryList)
+	}
+
+	_, err := this.invokeOnPartitions(ctx, requests, RESPONSE_VOID)
+
+	return err
+}
+
+// Every key in the map
+func (this *Map[K, V]) KeySet(ctx context.Context) ([]K, error) {
+
+	response, err := this.invoke(ctx, nil, EncodeMapKeySetRequest(this.name), RESPONSE_LIST_DATA)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMapObjects[K](this.client, this.name, DecodeMapListDataResponse(response))
+}
+
+// Every value in the map, including duplicates
+func (this *Map[K, V]) Values(ctx context.Context) ([]V, error) {
+
+	response, err := this.invoke(ctx, nil, EncodeMapValuesRequest(this.name), RESPONSE_LIST_DATA)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMapObjects[V](this.client, this.name, DecodeMapListDataResponse(response))
+}
+
+// Every entry in the map
+func (this *Map[K, V]) EntrySet(ctx context.Context) ([]Entry[K, V], error) {
+
+	response, err := this.invoke(ctx, nil, EncodeMapEntrySetRequest(this.name), RESPONSE_LIST_ENTRY)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.toEntries(DecodeMapListEntryResponse(response))
+}
+
+/*
+	Queries, evaluated on every member against the entries of the partitions it owns
+ */
+
+// The keys of the entries matching the predicate
+func (this *Map[K, V]) KeySetWithPredicate(ctx context.Context, predicate Predicate) ([]K, error) {
+
+	predicateData, err := this.toData(predicate, "predicate")
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := this.invoke(ctx, nil, EncodeMapKeySetWithPredicateRequest(this.name, predicateData), RESPONSE_LIST_DATA)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMapObjects[K](this.client, this.name, DecodeMapListDataResponse(response))
+}
+
+// The values of the entries matching the predicate
+func (this *Map[K, V]) ValuesWithPredicate(ctx context.Context, predicate Predicate) ([]V, error) {
+
+	predicateData, err := this.toData(predicate, "predicate")
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := this.invoke(ctx, nil, EncodeMapValuesWithPredicateRequest(this.name, predicateData), RESPONSE_LIST_DATA)
+	if err != nil {
+		return nil, err
+	}
+
+	return toMapObjects[V](this.client, this.name, DecodeMapListDataResponse(response))
+}
+
+// The entries matching the predicate
+func (this *Map[K, V]) EntrySetWithPredicate(ctx context.Context, predicate Predicate) ([]Entry[K, V], error) {
+
+	predicateData, err := this.toData(predicate, "predicate")
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := this.invoke(ctx, nil, EncodeMapEntriesWithPredicateRequest(this.name, predicateData), RESPONSE_LIST_ENTRY)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.toEntries(DecodeMapListEntryResponse(response))
+}
+
+// The keys of the current page, see PagingPredicate
+func (this *Map[K, V]) KeySetWithPagingPredicate(ctx context.Context, predicate *PagingPredicate) ([]K, error) {
+
+	page, err := this.queryPage(ctx, predicate, ITERATION_TYPE_KEY)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]K, len(page))
+	for i, entry := range page {
+		if keys[i], err = castMapObject[K](this.name, entry.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// The values of the current page, see PagingPredicate
+func (this *Map[K, V]) ValuesWithPagingPredicate(ctx context.Context, predicate *PagingPredicate) ([]V, error) {
+
+	page, err := this.queryPage(ctx, predicate, ITERATION_TYPE_VALUE)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]V, len(page))
+	for i, entry := range page {
+		if values[i], err = castMapObject[V](this.name, entry.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// The entries of the current page, see PagingPredicate
+func (this *Map[K, V]) EntrySetWithPagingPredicate(ctx context.Context, predicate *PagingPredicate) ([]Entry[K, V], error) {
+
+	page, err := this.queryPage(ctx, predicate, ITERATION_TYPE_ENTRY)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry[K, V], len(page))
+	for i, entry := range page {
+		if entries[i].Key, err = castMapObject[K](this.name, entry.Key); err != nil {
+			return nil, err
+		}
+		if entries[i].Value, err = castMapObject[V](this.name, entry.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// Send the paging predicate for the iteration type and select the current page from the merged member results
+func (this *Map[K, V]) queryPage(ctx context.Context, predicate *PagingPredicate, iterationType string) ([]Entry[any, any], error) {
+
+	if predicate == nil {
+		return nil, errors.New(fmt.Sprintf("Map %s does not allow nil predicates", this.name))
+	}
+
+	predicate.iterationType = iterationType
+
+	predicateData, err := this.toData(predicate, "predicate")
+	if err != nil {
+		return nil, err
+	}
+
+	service := this.client.SerializationService()
+	var entries []Entry[any, any]
+
+	if iterationType == ITERATION_TYPE_KEY {
+		response, err := this.invoke(ctx, nil, EncodeMapKeySetWithPagingPredicateRequest(this.name, predicateData), RESPONSE_LIST_DATA)
+		if err != nil {
+			return nil, err
+		}
+		for _, keyData := range DecodeMapListDataResponse(response) {
+			key, err := service.ToObject(keyData)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, Entry[any, any]{Key: key})
+		}
+	} else {
+		request := EncodeMapEntriesWithPagingPredicateRequest(this.name, predicateData)
+		if iterationType == ITERATION_TYPE_VALUE {
+			request = EncodeMapValuesWithPagingPredicateRequest(this.name, predicateData)
+		}
+		response, err := this.invoke(ctx, nil, request, RESPONSE_LIST_ENTRY)
+		if err != nil {
+			return nil, err
+		}
+		for _, dataEntry := range DecodeMapListEntryResponse(response) {
+			key, err := service.ToObject(dataEntry.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := service.ToObject(dataEntry.Value)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, Entry[any, any]{Key: key, Value: value})
+		}
+	}
+
+	return predicate.selectPage(entries)
+}
+
+/*
+	Expiry. A zero ttl or maxIdle never expires the entry, a negative one uses the value configured for the map on the cluster
+ */
+
+// Put with a time to live for the entry, returning the previous value. False when the key was not mapped
+func (this *Map[K, V]) PutWithTTL(ctx context.Context, key K, value V, ttl time.Duration) (V, bool, error) {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapPutRequest(this.name, keyData, valueData, MAP_THREAD_ID, durationMillis(ttl)))
+}
+
+// Put with a time to live, the entry also expires when not accessed for maxIdle. Requires Hazelcast 3.11 or later
+func (this *Map[K, V]) PutWithMaxIdle(ctx context.Context, key K, value V, ttl time.Duration, maxIdle time.Duration) (V, bool, error) {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapPutWithMaxIdleRequest(this.name, keyData, valueData, MAP_THREAD_ID, durationMillis(ttl), durationMillis(maxIdle)))
+}
+
+func (this *Map[K, V]) SetWithTTL(ctx context.Context, key K, value V, ttl time.Duration) error {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.invoke(ctx, keyData, EncodeMapSetRequest(this.name, keyData, valueData, MAP_THREAD_ID, durationMillis(ttl)), RESPONSE_VOID)
+
+	return err
+}
+
+// Set with a time to live, the entry also expires when not accessed for maxIdle. Requires Hazelcast 3.11 or later
+func (this *Map[K, V]) SetWithMaxIdle(ctx context.Context, key K, value V, ttl time.Duration, maxIdle time.Duration) error {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.invoke(ctx, keyData, EncodeMapSetWithMaxIdleRequest(this.name, keyData, valueData, MAP_THREAD_ID, durationMillis(ttl), durationMillis(maxIdle)), RESPONSE_DATA)
+
+	return err
+}
+
+func (this *Map[K, V]) PutIfAbsentWithTTL(ctx context.Context, key K, value V, ttl time.Duration) (V, bool, error) {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapPutIfAbsentRequest(this.name, keyData, valueData, MAP_THREAD_ID, durationMillis(ttl)))
+}
+
+// PutIfAbsent with a time to live, the entry also expires when not accessed for maxIdle. Requires Hazelcast 3.11 or later
+func (this *Map[K, V]) PutIfAbsentWithMaxIdle(ctx context.Context, key K, value V, ttl time.Duration, maxIdle time.Duration) (V, bool, error) {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	return this.invokeValue(ctx, keyData, EncodeMapPutIfAbsentWithMaxIdleRequest(this.name, keyData, valueData, MAP_THREAD_ID, durationMillis(ttl), durationMillis(maxIdle)))
+}
+
+// Put without writing the entry to a configured map store
+func (this *Map[K, V]) PutTransient(ctx context.Context, key K, value V, ttl time.Duration) error {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.invoke(ctx, keyData, EncodeMapPutTransientRequest(this.name, keyData, valueData, MAP_THREAD_ID, durationMillis(ttl)), RESPONSE_VOID)
+
+	return err
+}
+
+// PutTransient with a time to live, the entry also expires when not accessed for maxIdle. Requires Hazelcast 3.11 or later
+func (this *Map[K, V]) PutTransientWithMaxIdle(ctx context.Context, key K, value V, ttl time.Duration, maxIdle time.Duration) error {
+
+	keyData, valueData, err := this.toKeyValueData(key, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.invoke(ctx, keyData, EncodeMapPutTransientWithMaxIdleRequest(this.name, keyData, valueData, MAP_THREAD_ID, durationMillis(ttl), durationMillis(maxIdle)), RESPONSE_DATA)
+
+	return err
+}
+
+// Change the time to live of an existing entry, false when the key is not mapped. Requires Hazelcast 3.11 or later
+func (this *Map[K, V]) SetTTL(ctx context.Context, key K, ttl time.Duration) (bool, error) {
+
+	keyData, err := this.toData(key, "key")
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, keyData, EncodeMapSetTtlRequest(this.name, keyData, durationMillis(ttl)))
+}
+
+// Remove the entry from memory without deleting it from a configured map store, false when not evicted
+func (this *Map[K, V]) Evict(ctx context.Context, key K) (bool, error) {
+
+	keyData, err := this.toData(key, "key")
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, keyData, EncodeMapEvictRequest(this.name, keyData, MAP_THREAD_ID))
+}
+
+// Evict every entry, a configured map store is left unchanged
+func (this *Map[K, V]) EvictAll(ctx context.Context) error {
+
+	_, err := this.invoke(ctx, nil, EncodeMapEvictAllRequest(this.name), RESPONSE_VOID)
+
+	return err
+}
+
+// Write dirty entries to a configured write behind map store
+func (this *Map[K, V]) Flush(ctx context.Context) error {
+
+	_, err := this.invoke(ctx, nil, EncodeMapFlushRequest(this.name), RESPONSE_VOID)
+
+	return err
+}
+
+/*
+	Asynchronous operations, ctx applies to the operation rather than to Future.Get
+ */
+
+func (this *Map[K, V]) PutAsync(ctx context.Context, key K, value V) *Future[V] {
+
+	return newFuture(func() (V, bool, error) {
+		return this.Put(ctx, key, value)
+	})
+}
+
+func (this *Map[K, V]) PutWithTTLAsync(ctx context.Context, key K, value V, ttl time.Duration) *Future[V] {
+
+	return newFuture(func() (V, bool, error) {
+		return this.PutWithTTL(ctx, key, value, ttl)
+	})
+}
+
+func (this *Map[K, V]) GetAsync(ctx context.Context, key K) *Future[V] {
+
+	return newFuture(func() (V, bool, error) {
+		return this.Get(ctx, key)
+	})
+}
+
+func (this *Map[K, V]) RemoveAsync(ctx context.Context, key K) *Future[V] {
+
+	return newFuture(func() (V, bool, error) {
+		return this.Remove(ctx, key)
+	})
+}
+
+// The future bool result is true once the entry is set
+func (this *Map[K, V]) SetAsync(ctx context.Context, key K, value V) *Future[struct{}] {
+
+	return newFuture(func() (struct{}, bool, error) {
+		err := this.Set(ctx, key, value)
+		return struct{}{}, err == nil, err
+	})
+}
+
+func (this *Map[K, V]) SetWithTTLAsync(ctx context.Context, key K, value V, ttl time.Duration) *Future[struct{}] {
+
+	return newFuture(func() (struct{}, bool, error) {
+		err := this.SetWithTTL(ctx, key, value, ttl)
+		return struct{}{}, err == nil, err
+	})
+}
+
+// Destroy the map and its entries on the cluster
+func (this *Map[K, V]) Destroy(ctx context.Context) error {
+
+	return this.client.destroyProxy(ctx, this.name, MAP_SERVICE_NAME)
+}
+
+// Send the request to the owner of the key partition, or any member when key is nil
+func (this *Map[K, V]) invoke(ctx context.Context, key Data, request *ClientMessage, responseType uint16) (*ClientMessage, error) {
+
+	if key != nil {
+		request.SetPartitionId(this.client.PartitionService().GetDataPartitionId(key))
+	}
+
+	response, err := this.client.InvocationService().InvokeWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkResponse(response, responseType); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Send each request to the owner of its partition in parallel. The first failure cancels the requests still in flight
+func (this *Map[K, V]) invokeOnPartitions(ctx context.Context, requests map[int32]*ClientMessage, responseType uint16) ([]*ClientMessage, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var firstErr error
+	responses := make([]*ClientMessage, 0, len(requests))
+
+	for partitionId, request := range requests {
+
+		request.SetPartitionId(partitionId)
+
+		wg.Add(1)
+		go func(request *ClientMessage) {
+			defer wg.Done()
+
+			response, err := this.client.InvocationService().InvokeWithContext(ctx, request)
+			if err == nil {
+				err = checkResponse(response, responseType)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			responses = append(responses, response)
+		}(request)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return responses, nil
+}
+
+func (this *Map[K, V]) invokeBool(ctx context.Context, key Data, request *ClientMessage) (bool, error) {
+
+	response, err := this.invoke(ctx, key, request, RESPONSE_BOOLEAN)
+	if err != nil {
+		return false, err
+	}
+
+	return DecodeMapBooleanResponse(response), nil
+}
+
+// A nullable Data response, false when null
+func (this *Map[K, V]) invokeValue(ctx context.Context, key Data, request *ClientMessage) (V, bool, error) {
+
+	var value V
+
+	response, err := this.invoke(ctx, key, request, RESPONSE_DATA)
+	if err != nil {
+		return value, false, err
+	}
+
+	data := DecodeMapDataResponse(response)
+	if data == nil {
+		return value, false, nil
+	}
+
+	value, err = toMapObject[V](this.client, this.name, data)
+	if err != nil {
+		return value, false, err
+	}
+
+	return value, true, nil
+}
+
+func (this *Map[K, V]) toData(v interface{}, kind string) (Data, error) {
+
+	data, err := this.client.SerializationService().ToData(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, errors.New(fmt.Sprintf("Map %s does not allow nil %ss", this.name, kind))
+	}
+
+	return data, nil
+}
+
+func (this *Map[K, V]) toKeyValueData(key K, value V) (Data, Data, error) {
+
+	keyData, err := this.toData(key, "key")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	valueData, err := this.toData(value, "value")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return keyData, valueData, nil
+}
+
+func (this *Map[K, V]) toEntries(dataEntries []DataEntry) ([]Entry[K, V], error) {
+
+	entries := make([]Entry[K, V], len(dataEntries))
+	for i, dataEntry := range dataEntries {
+		key, err := toMapObject[K](this.client, this.name, dataEntry.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := toMapObject[V](this.client, this.name, dataEntry.Value)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = Entry[K, V]{Key: key, Value: value}
+	}
+
+	return entries, nil
+}
+
+// Milliseconds as sent to the cluster, rounded up so a short positive duration does not become 0 and never expire
+func durationMillis(d time.Duration) int64 {
+
+	if d < 0 {
+		return MAP_DEFAULT_TTL
+	}
+
+	millis := int64(d / time.Millisecond)
+	if millis == 0 && d > 0 {
+		return 1
+	}
+
+	return millis
+}
+
+// Deserialize a key or value, a function as Go methods cannot have type parameters
+func toMapObject[T any](client *HazelcastClient, name string, data Data) (T, error) {
+
+	object, err := client.SerializationService().ToObject(data)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return castMapObject[T](name, object)
+}
+
+func castMapObject[T any](name string, object interface{}) (T, error) {
+
+	var result T
+
+	if object == nil {
+		return result, nil
+	}
+
+	result, ok := castObject[T](object)
+	if !ok {
+		return result, errors.New(fmt.Sprintf("Map %s entry of type %T is not a %T", name, object, result))
+	}
+
+	return result, nil
+}
+
+func toMapObjects[T any](client *HazelcastClient, name string, dataList []Data) ([]T, error) {
+
+	objects := make([]T, len(dataList))
+	for i, data := range dataList {
+		object, err := toMapObject[T](client, name, data)
+		if err != nil {
+			return nil, err
+		}
+		objects[i] = object
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,393 @@
+package hz
+
+const (
+
+	// Locks are not supported, every operation is sent as the same thread
+	MAP_THREAD_ID = 1
+
+	// Use the TTL configured for the map on the cluster
+	MAP_DEFAULT_TTL = -1
+)
+
+// Response: RESPONSE_DATA, the previous value or null
+func EncodeMapPutRequest(name string, key Data, value Data, threadId int64, ttlMillis int64) *ClientMessage {
+
+	return encodeMapKeyValueTtlRequest(CLIENT_MAP_PUT, name, key, value, threadId, ttlMillis)
+}
+
+// Response: RESPONSE_VOID
+func EncodeMapSetRequest(name string, key Data, value Data, threadId int64, ttlMillis int64) *ClientMessage {
+
+	return encodeMapKeyValueTtlRequest(CLIENT_MAP_SET, name, key, value, threadId, ttlMillis)
+}
+
+// Response: RESPONSE_DATA, the current value or null when the value was put
+func EncodeMapPutIfAbsentRequest(name string, key Data, value Data, threadId int64, ttlMillis int64) *ClientMessage {
+
+	return encodeMapKeyValueTtlRequest(CLIENT_MAP_PUT_IF_ABSENT, name, key, value, threadId, ttlMillis)
+}
+
+// Response: RESPONSE_DATA, the value or null
+func EncodeMapGetRequest(name string, key Data, threadId int64) *ClientMessage {
+
+	message := encodeMapKeyRequest(CLIENT_MAP_GET, name, key, threadId)
+	message.SetIsRetryable(true)
+
+	return message
+}
+
+// Response: RESPONSE_DATA, the removed value or null
+func EncodeMapRemoveRequest(name string, key Data, threadId int64) *ClientMessage {
+
+	return encodeMapKeyRequest(CLIENT_MAP_REMOVE, name, key, threadId)
+}
+
+// Response: RESPONSE_VOID
+func EncodeMapDeleteRequest(name string, key Data, threadId int64) *ClientMessage {
+
+	return encodeMapKeyRequest(CLIENT_MAP_DELETE, name, key, threadId)
+}
+
+// Response: RESPONSE_BOOLEAN
+func EncodeMapContainsKeyRequest(name string, key Data, threadId int64) *ClientMessage {
+
+	message := encodeMapKeyRequest(CLIENT_MAP_CONTAINS_KEY, name, key, threadId)
+	message.SetIsRetryable(true)
+
+	return message
+}
+
+// Response: RESPONSE_BOOLEAN
+func EncodeMapContainsValueRequest(name string, value Data) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(value))
+	message.SetMessageType(CLIENT_MAP_CONTAINS_VALUE)
+	message.SetIsRetryable(true)
+	message.AppendStr(&name)
+	message.AppendByteArray(value)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_BOOLEAN, true when the key was mapped to value and has been removed
+func EncodeMapRemoveIfSameRequest(name string, key Data, value Data, threadId int64) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(key) + CalculateSizeData(value) + INT64_SIZE_IN_BYTES)
+	message.SetMessageType(CLIENT_MAP_REMOVE_IF_SAME)
+	message.AppendStr(&name)
+	message.AppendByteArray(key)
+	message.AppendByteArray(value)
+	message.AppendInt64(uint64(threadId))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_DATA, the previous value or null when the key was not mapped and nothing was replaced
+func EncodeMapReplaceRequest(name string, key Data, value Data, threadId int64) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(key) + CalculateSizeData(value) + INT64_SIZE_IN_BYTES)
+	message.SetMessageType(CLIENT_MAP_REPLACE)
+	message.AppendStr(&name)
+	message.AppendByteArray(key)
+	message.AppendByteArray(value)
+	message.AppendInt64(uint64(threadId))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_BOOLEAN, true when the key was mapped to testValue and has been replaced
+func EncodeMapReplaceIfSameRequest(name string, key Data, testValue Data, value Data, threadId int64) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(key) + CalculateSizeData(testValue) + CalculateSizeData(value) + INT64_SIZE_IN_BYTES)
+	message.SetMessageType(CLIENT_MAP_REPLACE_IF_SAME)
+	message.AppendStr(&name)
+	message.AppendByteArray(key)
+	message.AppendByteArray(testValue)
+	message.AppendByteArray(value)
+	message.AppendInt64(uint64(threadId))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_INTEGER
+func EncodeMapSizeRequest(name string) *ClientMessage {
+
+	message := encodeMapNameRequest(CLIENT_MAP_SIZE, name)
+	message.SetIsRetryable(true)
+
+	return message
+}
+
+// Response: RESPONSE_BOOLEAN
+func EncodeMapIsEmptyRequest(name string) *ClientMessage {
+
+	message := encodeMapNameRequest(CLIENT_MAP_IS_EMPTY, name)
+	message.SetIsRetryable(true)
+
+	return message
+}
+
+// Response: RESPONSE_VOID
+func EncodeMapClearRequest(name string) *ClientMessage {
+
+	return encodeMapNameRequest(CLIENT_MAP_CLEAR, name)
+}
+
+// Response: RESPONSE_VOID, the entry is not written to the map store
+func EncodeMapPutTransientRequest(name string, key Data, value Data, threadId int64, ttlMillis int64) *ClientMessage {
+
+	return encodeMapKeyValueTtlRequest(CLIENT_MAP_PUT_TRANSIENT, name, key, value, threadId, ttlMillis)
+}
+
+// Response: RESPONSE_DATA, the previous value or null
+func EncodeMapPutWithMaxIdleRequest(name string, key Data, value Data, threadId int64, ttlMillis int64, maxIdleMillis int64) *ClientMessage {
+
+	return encodeMapKeyValueMaxIdleRequest(CLIENT_MAP_PUT_WITH_MAX_IDLE, name, key, value, threadId, ttlMillis, maxIdleMillis)
+}
+
+// Response: RESPONSE_DATA, always null
+func EncodeMapPutTransientWithMaxIdleRequest(name string, key Data, value Data, threadId int64, ttlMillis int64, maxIdleMillis int64) *ClientMessage {
+
+	return encodeMapKeyValueMaxIdleRequest(CLIENT_MAP_PUT_TRANSIENT_WITH_MAX_IDLE, name, key, value, threadId, ttlMillis, maxIdleMillis)
+}
+
+// Response: RESPONSE_DATA, the current value or null when the value was put
+func EncodeMapPutIfAbsentWithMaxIdleRequest(name string, key Data, value Data, threadId int64, ttlMillis int64, maxIdleMillis int64) *ClientMessage {
+
+	return encodeMapKeyValueMaxIdleRequest(CLIENT_MAP_PUT_IF_ABSENT_WITH_MAX_IDLE, name, key, value, threadId, ttlMillis, maxIdleMillis)
+}
+
+// Response: RESPONSE_DATA, always null
+func EncodeMapSetWithMaxIdleRequest(name string, key Data, value Data, threadId int64, ttlMillis int64, maxIdleMillis int64) *ClientMessage {
+
+	return encodeMapKeyValueMaxIdleRequest(CLIENT_MAP_SET_WITH_MAX_IDLE, name, key, value, threadId, ttlMillis, maxIdleMillis)
+}
+
+// Response: RESPONSE_BOOLEAN, false when the key is not mapped
+func EncodeMapSetTtlRequest(name string, key Data, ttlMillis int64) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(key) + INT64_SIZE_IN_BYTES)
+	message.SetMessageType(CLIENT_MAP_SET_TTL)
+	message.AppendStr(&name)
+	message.AppendByteArray(key)
+	message.AppendInt64(uint64(ttlMillis))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_BOOLEAN, true when the entry was evicted
+func EncodeMapEvictRequest(name string, key Data, threadId int64) *ClientMessage {
+
+	return encodeMapKeyRequest(CLIENT_MAP_EVICT, name, key, threadId)
+}
+
+// Response: RESPONSE_VOID
+func EncodeMapEvictAllRequest(name string) *ClientMessage {
+
+	return encodeMapNameRequest(CLIENT_MAP_EVICT_ALL, name)
+}
+
+// Response: RESPONSE_VOID, once dirty entries are written to the map store
+func EncodeMapFlushRequest(name string) *ClientMessage {
+
+	return encodeMapNameRequest(CLIENT_MAP_FLUSH, name)
+}
+
+// Response: RESPONSE_LIST_DATA
+func EncodeMapKeySetRequest(name string) *ClientMessage {
+
+	message := encodeMapNameRequest(CLIENT_MAP_KEY_SET, name)
+	message.SetIsRetryable(true)
+
+	return message
+}
+
+// Response: RESPONSE_LIST_DATA
+func EncodeMapValuesRequest(name string) *ClientMessage {
+
+	message := encodeMapNameRequest(CLIENT_MAP_VALUES, name)
+	message.SetIsRetryable(true)
+
+	return message
+}
+
+// Response: RESPONSE_LIST_ENTRY
+func EncodeMapEntrySetRequest(name string) *ClientMessage {
+
+	message := encodeMapNameRequest(CLIENT_MAP_ENTRY_SET, name)
+	message.SetIsRetryable(true)
+
+	return message
+}
+
+// Response: RESPONSE_LIST_ENTRY, the mapped keys only. The cluster reads the keys from the partition the request is sent to,
+// so every key must belong to that partition
+func EncodeMapGetAllRequest(name string, keys []Data) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeDataList(keys))
+	message.SetMessageType(CLIENT_MAP_GET_ALL)
+	message.SetIsRetryable(true)
+	message.AppendStr(&name)
+	message.AppendDataList(keys)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_VOID. As with getAll every key must belong to the partition the request is sent to
+func EncodeMapPutAllRequest(name string, entries []DataEntry) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeEntryList(entries))
+	message.SetMessageType(CLIENT_MAP_PUT_ALL)
+	message.AppendStr(&name)
+	message.AppendEntryList(entries)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_LIST_DATA
+func EncodeMapKeySetWithPredicateRequest(name string, predicate Data) *ClientMessage {
+
+	return encodeMapPredicateRequest(CLIENT_MAP_KEY_SET_WITH_PREDICATE, name, predicate)
+}
+
+// Response: RESPONSE_LIST_DATA
+func EncodeMapValuesWithPredicateRequest(name string, predicate Data) *ClientMessage {
+
+	return encodeMapPredicateRequest(CLIENT_MAP_VALUES_WITH_PREDICATE, name, predicate)
+}
+
+// Response: RESPONSE_LIST_ENTRY
+func EncodeMapEntriesWithPredicateRequest(name string, predicate Data) *ClientMessage {
+
+	return encodeMapPredicateRequest(CLIENT_MAP_ENTRIES_WITH_PREDICATE, name, predicate)
+}
+
+// Response: RESPONSE_LIST_DATA, the keys of the page from each member
+func EncodeMapKeySetWithPagingPredicateRequest(name string, predicate Data) *ClientMessage {
+
+	return encodeMapPredicateRequest(CLIENT_MAP_KEY_SET_WITH_PAGING_PREDICATE, name, predicate)
+}
+
+// Response: RESPONSE_LIST_ENTRY, the entries of the page from each member so the values can be sorted with the keys
+func EncodeMapValuesWithPagingPredicateRequest(name string, predicate Data) *ClientMessage {
+
+	return encodeMapPredicateRequest(CLIENT_MAP_VALUES_WITH_PAGING_PREDICATE, name, predicate)
+}
+
+// Response: RESPONSE_LIST_ENTRY, the entries of the page from each member
+func EncodeMapEntriesWithPagingPredicateRequest(name string, predicate Data) *ClientMessage {
+
+	return encodeMapPredicateRequest(CLIENT_MAP_ENTRIES_WITH_PAGING_PREDICATE, name, predicate)
+}
+
+func encodeMapNameRequest(messageType uint16, name string) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name))
+	message.SetMessageType(messageType)
+	message.AppendStr(&name)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+func encodeMapKeyRequest(messageType uint16, name string, key Data, threadId int64) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(key) + INT64_SIZE_IN_BYTES)
+	message.SetMessageType(messageType)
+	message.AppendStr(&name)
+	message.AppendByteArray(key)
+	message.AppendInt64(uint64(threadId))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+func encodeMapPredicateRequest(messageType uint16, name string, predicate Data) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(predicate))
+	message.SetMessageType(messageType)
+	message.SetIsRetryable(true)
+	message.AppendStr(&name)
+	message.AppendByteArray(predicate)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+func encodeMapKeyValueTtlRequest(messageType uint16, name string, key Data, value Data, threadId int64, ttlMillis int64) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(key) + CalculateSizeData(value) + INT64_SIZE_IN_BYTES + INT64_SIZE_IN_BYTES)
+	message.SetMessageType(messageType)
+	message.AppendStr(&name)
+	message.AppendByteArray(key)
+	message.AppendByteArray(value)
+	message.AppendInt64(uint64(threadId))
+	message.AppendInt64(uint64(ttlMillis))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+func encodeMapKeyValueMaxIdleRequest(messageType uint16, name string, key Data, value Data, threadId int64, ttlMillis int64, maxIdleMillis int64) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(key) + CalculateSizeData(value) + 3 * INT64_SIZE_IN_BYTES)
+	message.SetMessageType(messageType)
+	message.AppendStr(&name)
+	message.AppendByteArray(key)
+	message.AppendByteArray(value)
+	message.AppendInt64(uint64(threadId))
+	message.AppendInt64(uint64(ttlMillis))
+	message.AppendInt64(uint64(maxIdleMillis))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+/*
+	Response decoders
+ */
+
+func DecodeMapBooleanResponse(response *ClientMessage) bool {
+
+	return response.readBool()
+}
+
+func DecodeMapIntegerResponse(response *ClientMessage) int32 {
+
+	return response.readInt()
+}
+
+// Nil when the response holds null
+func DecodeMapDataResponse(response *ClientMessage) Data {
+
+	return response.readNullableData()
+}
+
+func DecodeMapListDataResponse(response *ClientMessage) []Data {
+
+	return response.readDataList()
+}
+
+func DecodeMapListEntryResponse(response *ClientMessage) []DataEntry {
+
+	return response.readEntryList()
+}
@@ -0,0 +1,684 @@
+package hz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A map request as received by the stub
+type mapRequest struct {
+
+	messageType uint16
+	partitionId int32
+	keys        []Data
+	ttl         int64
+	maxIdle     int64
+}
+
+// An in memory map behind the stub member, keyed by the serialized key
+type stubMap struct {
+
+	mutex    sync.Mutex
+	entries  map[string]Data
+	requests []mapRequest
+}
+
+func newStubMap() *stubMap {
+
+	return &stubMap{entries: make(map[string]Data)}
+}
+
+// The requests received since the last call
+func (this *stubMap) received() []mapRequest {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	requests := this.requests
+	this.requests = nil
+
+	return requests
+}
+
+func (this *stubMap) handle(request *ClientMessage) []*ClientMessage {
+
+	messageType := request.GetMessageType()
+	if messageType < CLIENT_MAP_PUT || messageType > CLIENT_MAP_SET_WITH_MAX_IDLE {
+		return nil
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	received := mapRequest{messageType: messageType, partitionId: request.GetPartitionId()}
+	defer func() { this.requests = append(this.requests, received) }()
+
+	request.readString()
+
+	readKey := func() string {
+		key := request.readData()
+		received.keys = append(received.keys, key)
+		return string(key)
+	}
+
+	switch messageType {
+	case CLIENT_MAP_GET, CLIENT_MAP_CONTAINS_KEY, CLIENT_MAP_REMOVE, CLIENT_MAP_DELETE, CLIENT_MAP_EVICT:
+		key := readKey()
+		request.readInt64()
+		value, found := this.entries[key]
+		switch messageType {
+		case CLIENT_MAP_GET:
+			return []*ClientMessage{dataResponse(value)}
+		case CLIENT_MAP_CONTAINS_KEY:
+			return []*ClientMessage{boolResponse(found)}
+		}
+		delete(this.entries, key)
+		if messageType == CLIENT_MAP_REMOVE {
+			return []*ClientMessage{dataResponse(value)}
+		} else if messageType == CLIENT_MAP_EVICT {
+			return []*ClientMessage{boolResponse(found)}
+		}
+		return []*ClientMessage{voidResponse()}
+
+	case CLIENT_MAP_PUT, CLIENT_MAP_SET, CLIENT_MAP_PUT_IF_ABSENT, CLIENT_MAP_PUT_TRANSIENT,
+		CLIENT_MAP_PUT_WITH_MAX_IDLE, CLIENT_MAP_SET_WITH_MAX_IDLE, CLIENT_MAP_PUT_IF_ABSENT_WITH_MAX_IDLE, CLIENT_MAP_PUT_TRANSIENT_WITH_MAX_IDLE:
+		key := readKey()
+		value := request.readData()
+		request.readInt64()
+		received.ttl = request.readInt64()
+		if messageType >= CLIENT_MAP_PUT_WITH_MAX_IDLE {
+			received.maxIdle = request.readInt64()
+		}
+		previous, found := this.entries[key]
+		if !found || (messageType != CLIENT_MAP_PUT_IF_ABSENT && messageType != CLIENT_MAP_PUT_IF_ABSENT_WITH_MAX_IDLE) {
+			this.entries[key] = value
+		}
+		switch messageType {
+		case CLIENT_MAP_SET, CLIENT_MAP_PUT_TRANSIENT:
+			return []*ClientMessage{voidResponse()}
+		case CLIENT_MAP_SET_WITH_MAX_IDLE, CLIENT_MAP_PUT_TRANSIENT_WITH_MAX_IDLE:
+			// The 3.11 codecs answer these with a null Data
+			return []*ClientMessage{dataResponse(nil)}
+		}
+		return []*ClientMessage{dataResponse(previous)}
+
+	case CLIENT_MAP_REPLACE, CLIENT_MAP_REPLACE_IF_SAME, CLIENT_MAP_REMOVE_IF_SAME:
+		key := readKey()
+		previous, found := this.entries[key]
+		switch messageType {
+		case CLIENT_MAP_REPLACE:
+			value := request.readData()
+			if found {
+				this.entries[key] = value
+			}
+			return []*ClientMessage{dataResponse(previous)}
+		case CLIENT_MAP_REPLACE_IF_SAME:
+			test, value := request.readData(), request.readData()
+			same := found && string(previous) == string(test)
+			if same {
+				this.entries[key] = value
+			}
+			return []*ClientMessage{boolResponse(same)}
+		}
+		same := found && string(previous) == string(request.readData())
+		if same {
+			delete(this.entries, key)
+		}
+		return []*ClientMessage{boolResponse(same)}
+
+	case CLIENT_MAP_SET_TTL:
+		key := readKey()
+		received.ttl = request.readInt64()
+		_, found := this.entries[key]
+		return []*ClientMessage{boolResponse(found)}
+
+	case CLIENT_MAP_CONTAINS_VALUE:
+		value := string(request.readData())
+		for _, existing := range this.entries {
+			if string(existing) == value {
+				return []*ClientMessage{boolResponse(true)}
+			}
+		}
+		return []*ClientMessage{boolResponse(false)}
+
+	case CLIENT_MAP_SIZE:
+		return []*ClientMessage{intResponse(int32(len(this.entries)))}
+	case CLIENT_MAP_IS_EMPTY:
+		return []*ClientMessage{boolResponse(len(this.entries) == 0)}
+	case CLIENT_MAP_CLEAR, CLIENT_MAP_EVICT_ALL:
+		this.entries = make(map[string]Data)
+		return []*ClientMessage{voidResponse()}
+	case CLIENT_MAP_FLUSH:
+		return []*ClientMessage{voidResponse()}
+
+	case CLIENT_MAP_KEY_SET, CLIENT_MAP_VALUES, CLIENT_MAP_ENTRY_SET:
+		var list []Data
+		var entries []DataEntry
+		for key, value := range this.entries {
+			entries = append(entries, DataEntry{Key: Data(key), Value: value})
+			if messageType == CLIENT_MAP_KEY_SET {
+				list = append(list, Data(key))
+			} else {
+				list = append(list, value)
+			}
+		}
+		if messageType == CLIENT_MAP_ENTRY_SET {
+			return []*ClientMessage{entryListResponse(entries)}
+		}
+		return []*ClientMessage{listResponse(list)}
+
+	case CLIENT_MAP_GET_ALL:
+		var entries []DataEntry
+		for _, key := range request.readDataList() {
+			received.keys = append(received.keys, key)
+			if value, found := this.entries[string(key)]; found {
+				entries = append(entries, DataEntry{Key: key, Value: value})
+			}
+		}
+		return []*ClientMessage{entryListResponse(entries)}
+
+	case CLIENT_MAP_PUT_ALL:
+		for _, entry := range request.readEntryList() {
+			received.keys = append(received.keys, entry.Key)
+			this.entries[string(entry.Key)] = entry.Value
+		}
+		return []*ClientMessage{voidResponse()}
+	}
+
+	return nil
+}
+
+func TestMapOperations(t *testing.T) {
+
+	stub := newStubMap()
+	client, _ := newStubClient(t, stub.handle)
+	ctx := context.Background()
+
+	m, err := GetMap[string, string](ctx, client, "colors")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyData, _ := client.SerializationService().ToData("apple")
+	nameData, _ := client.SerializationService().ToData(m.Name())
+	keyPartition := client.PartitionService().GetDataPartitionId(keyData)
+	if keyPartition < 0 || keyPartition == client.PartitionService().GetDataPartitionId(nameData) {
+		t.Fatalf("key partition %d does not tell the key from the name", keyPartition)
+	}
+
+	expect := func(value string, found bool, wantValue string, wantFound bool) error {
+		if value != wantValue || found != wantFound {
+			return fmt.Errorf("got %q, %v, want %q, %v", value, found, wantValue, wantFound)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name        string
+		call        func() error
+		messageType uint16
+		partitionId int32
+	}{
+		{"get absent", func() error {
+			value, found, err := m.Get(ctx, "apple")
+			if err != nil {
+				return err
+			}
+			return expect(value, found, "", false)
+		}, CLIENT_MAP_GET, keyPartition},
+		{"contains absent key", func() error {
+			if found, err := m.ContainsKey(ctx, "apple"); err != nil || found {
+				return fmt.Errorf("got %v, %v", found, err)
+			}
+			return nil
+		}, CLIENT_MAP_CONTAINS_KEY, keyPartition},
+		{"remove absent", func() error {
+			value, found, err := m.Remove(ctx, "apple")
+			if err != nil {
+				return err
+			}
+			return expect(value, found, "", false)
+		}, CLIENT_MAP_REMOVE, keyPartition},
+		{"put", func() error {
+			value, found, err := m.Put(ctx, "apple", "green")
+			if err != nil {
+				return err
+			}
+			return expect(value, found, "", false)
+		}, CLIENT_MAP_PUT, keyPartition},
+		{"put replacing", func() error {
+			value, found, err := m.Put(ctx, "apple", "red")
+			if err != nil {
+				return err
+			}
+			return expect(value, found, "green", true)
+		}, CLIENT_MAP_PUT, keyPartition},
+		{"get present", func() error {
+			value, found, err := m.Get(ctx, "apple")
+			if err != nil {
+				return err
+			}
+			return expect(value, found, "red", true)
+		}, CLIENT_MAP_GET, keyPartition},
+		{"contains key", func() error {
+			if found, err := m.ContainsKey(ctx, "apple"); err != nil || !found {
+				return fmt.Errorf("got %v, %v", found, err)
+			}
+			return nil
+		}, CLIENT_MAP_CONTAINS_KEY, keyPartition},
+		{"contains value", func() error {
+			if found, err := m.ContainsValue(ctx, "red"); err != nil || !found {
+				return fmt.Errorf("got %v, %v", found, err)
+			}
+			return nil
+		}, CLIENT_MAP_CONTAINS_VALUE, -1},
+		{"put if absent when present", func() error {
+			value, found, err := m.PutIfAbsent(ctx, "apple", "yellow")
+			if err != nil {
+				return err
+			}
+			return expect(value, found, "red", true)
+		}, CLIENT_MAP_PUT_IF_ABSENT, keyPartition},
+		{"replace", func() error {
+			value, found, err := m.Replace(ctx, "apple", "yellow")
+			if err != nil {
+				return err
+			}
+			return expect(value, found, "red", true)
+		}, CLIENT_MAP_REPLACE, keyPartition},
+		{"replace if same", func() error {
+			if replaced, err := m.ReplaceIfSame(ctx, "apple", "yellow", "red"); err != nil || !replaced {
+				return fmt.Errorf("got %v, %v", replaced, err)
+			}
+			return nil
+		}, CLIENT_MAP_REPLACE_IF_SAME, keyPartition},
+		{"size", func() error {
+			if size, err := m.Size(ctx); err != nil || size != 1 {
+				return fmt.Errorf("got %d, %v", size, err)
+			}
+			return nil
+		}, CLIENT_MAP_SIZE, -1},
+		{"remove if same with another value", func() error {
+			if removed, err := m.RemoveIfSame(ctx, "apple", "green"); err != nil || removed {
+				return fmt.Errorf("got %v, %v", removed, err)
+			}
+			return nil
+		}, CLIENT_MAP_REMOVE_IF_SAME, keyPartition},
+		{"remove present", func() error {
+			value, found, err := m.Remove(ctx, "apple")
+			if err != nil {
+				return err
+			}
+			return expect(value, found, "red", true)
+		}, CLIENT_MAP_REMOVE, keyPartition},
+		{"set", func() error { return m.Set(ctx, "apple", "green") }, CLIENT_MAP_SET, keyPartition},
+		{"evict", func() error {
+			if evicted, err := m.Evict(ctx, "apple"); err != nil || !evicted {
+				return fmt.Errorf("got %v, %v", evicted, err)
+			}
+			return nil
+		}, CLIENT_MAP_EVICT, keyPartition},
+		{"delete", func() error { return m.Delete(ctx, "apple") }, CLIENT_MAP_DELETE, keyPartition},
+		{"is empty", func() error {
+			if empty, err := m.IsEmpty(ctx); err != nil || !empty {
+				return fmt.Errorf("got %v, %v", empty, err)
+			}
+			return nil
+		}, CLIENT_MAP_IS_EMPTY, -1},
+		{"clear", func() error { return m.Clear(ctx) }, CLIENT_MAP_CLEAR, -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			if err := test.call(); err != nil {
+				t.Fatal(err)
+			}
+
+			requests := stub.received()
+			if len(requests) != 1 {
+				t.Fatalf("got %d requests", len(requests))
+			}
+			if requests[0].messageType != test.messageType {
+				t.Fatalf("message type: got 0x%04x, want 0x%04x", requests[0].messageType, test.messageType)
+			}
+			if requests[0].partitionId != test.partitionId {
+				t.Fatalf("partition id: got %d, want %d", requests[0].partitionId, test.partitionId)
+			}
+			if len(requests[0].keys) > 0 && string(requests[0].keys[0]) != string(keyData) {
+				t.Fatal("request does not carry the serialized key")
+			}
+		})
+	}
+}
+
+func TestDurationMillis(t *testing.T) {
+
+	tests := []struct {
+		duration time.Duration
+		millis   int64
+	}{
+		{-time.Hour, MAP_DEFAULT_TTL},
+		{-time.Nanosecond, MAP_DEFAULT_TTL},
+		{0, 0},
+		{time.Nanosecond, 1},
+		{999 * time.Microsecond, 1},
+		{time.Millisecond, 1},
+		{1999 * time.Microsecond, 1},
+		{2 * time.Second, 2000},
+	}
+
+	for _, test := range tests {
+		if millis := durationMillis(test.duration); millis != test.millis {
+			t.Errorf("%v: got %d, want %d", test.duration, millis, test.millis)
+		}
+	}
+}
+
+func TestMapExpiry(t *testing.T) {
+
+	stub := newStubMap()
+	client, _ := newStubClient(t, stub.handle)
+	ctx := context.Background()
+
+	m, err := GetMap[string, string](ctx, client, "sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		call        func() error
+		messageType uint16
+		ttl         int64
+		maxIdle     int64
+	}{
+		{"put uses the map ttl", func() error {
+			_, _, err := m.Put(ctx, "a", "1")
+			return err
+		}, CLIENT_MAP_PUT, MAP_DEFAULT_TTL, 0},
+		{"zero ttl never expires", func() error {
+			_, _, err := m.PutWithTTL(ctx, "a", "1", 0)
+			return err
+		}, CLIENT_MAP_PUT, 0, 0},
+		{"negative ttl uses the map ttl", func() error {
+			return m.SetWithTTL(ctx, "a", "1", -time.Second)
+		}, CLIENT_MAP_SET, MAP_DEFAULT_TTL, 0},
+		{"put if absent with ttl", func() error {
+			_, _, err := m.PutIfAbsentWithTTL(ctx, "b", "2", time.Minute)
+			return err
+		}, CLIENT_MAP_PUT_IF_ABSENT, 60000, 0},
+		{"put transient", func() error {
+			return m.PutTransient(ctx, "a", "1", 1500*time.Microsecond)
+		}, CLIENT_MAP_PUT_TRANSIENT, 1, 0},
+		{"put with max idle", func() error {
+			_, _, err := m.PutWithMaxIdle(ctx, "a", "1", time.Second, 500*time.Microsecond)
+			return err
+		}, CLIENT_MAP_PUT_WITH_MAX_IDLE, 1000, 1},
+		{"put if absent with max idle", func() error {
+			_, _, err := m.PutIfAbsentWithMaxIdle(ctx, "c", "3", 0, -1)
+			return err
+		}, CLIENT_MAP_PUT_IF_ABSENT_WITH_MAX_IDLE, 0, MAP_DEFAULT_TTL},
+		{"set with max idle", func() error {
+			return m.SetWithMaxIdle(ctx, "a", "1", -1, time.Second)
+		}, CLIENT_MAP_SET_WITH_MAX_IDLE, MAP_DEFAULT_TTL, 1000},
+		{"put transient with max idle", func() error {
+			return m.PutTransientWithMaxIdle(ctx, "a", "1", time.Second, 0)
+		}, CLIENT_MAP_PUT_TRANSIENT_WITH_MAX_IDLE, 1000, 0},
+		{"set ttl", func() error {
+			if found, err := m.SetTTL(ctx, "a", 0); err != nil || !found {
+				return fmt.Errorf("got %v, %v", found, err)
+			}
+			return nil
+		}, CLIENT_MAP_SET_TTL, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			if err := test.call(); err != nil {
+				t.Fatal(err)
+			}
+
+			requests := stub.received()
+			if len(requests) != 1 || requests[0].messageType != test.messageType {
+				t.Fatalf("got %+v, want message type 0x%04x", requests, test.messageType)
+			}
+			if requests[0].ttl != test.ttl || requests[0].maxIdle != test.maxIdle {
+				t.Fatalf("got ttl %d max idle %d, want %d and %d", requests[0].ttl, requests[0].maxIdle, test.ttl, test.maxIdle)
+			}
+		})
+	}
+}
+
+// SetWithMaxIdle and PutTransientWithMaxIdle are answered with a null Data rather than void
+func TestMapMaxIdleResponseType(t *testing.T) {
+
+	client, _ := newStubClient(t, func(request *ClientMessage) []*ClientMessage {
+
+		switch request.GetMessageType() {
+		case CLIENT_MAP_SET_WITH_MAX_IDLE, CLIENT_MAP_PUT_TRANSIENT_WITH_MAX_IDLE:
+			return []*ClientMessage{voidResponse()}
+		}
+		return nil
+	})
+	ctx := context.Background()
+
+	m, err := GetMap[string, string](ctx, client, "sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetWithMaxIdle(ctx, "a", "1", 0, time.Second); err == nil {
+		t.Error("SetWithMaxIdle accepted a void response")
+	}
+	if err := m.PutTransientWithMaxIdle(ctx, "a", "1", 0, time.Second); err == nil {
+		t.Error("PutTransientWithMaxIdle accepted a void response")
+	}
+}
+
+func TestMapAsync(t *testing.T) {
+
+	stub := newStubMap()
+	client, _ := newStubClient(t, stub.handle)
+	ctx := context.Background()
+
+	m, err := GetMap[string, string](ctx, client, "colors")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := m.SetAsync(ctx, "apple", "green").Get(ctx); err != nil || !ok {
+		t.Fatalf("set: got %v, %v", ok, err)
+	}
+	if previous, found, err := m.PutWithTTLAsync(ctx, "apple", "red", time.Minute).Get(ctx); err != nil || !found || previous != "green" {
+		t.Fatalf("put: got %q, %v, %v", previous, found, err)
+	}
+
+	future := m.GetAsync(ctx, "apple")
+	<-future.Done()
+	if value, found, err := future.Get(ctx); err != nil || !found || value != "red" {
+		t.Fatalf("get: got %q, %v, %v", value, found, err)
+	}
+
+	if value, found, err := m.RemoveAsync(ctx, "apple").Get(ctx); err != nil || !found || value != "red" {
+		t.Fatalf("remove: got %q, %v, %v", value, found, err)
+	}
+	if _, found, err := m.GetAsync(ctx, "apple").Get(ctx); err != nil || found {
+		t.Fatalf("get removed: got %v, %v", found, err)
+	}
+}
+
+func TestFutureContext(t *testing.T) {
+
+	// The member never answers a get
+	client, _ := newConfiguredStubClient(t, func(request *ClientMessage) []*ClientMessage {
+
+		if request.GetMessageType() == CLIENT_MAP_GET {
+			return []*ClientMessage{}
+		}
+		return nil
+	}, func(config *ClientConfig) {
+		config.Logger = noLogging{}
+	})
+
+	m, err := GetMap[string, string](context.Background(), client, "colors")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("get returns when its ctx is done", func(t *testing.T) {
+
+		operationCtx, cancelOperation := context.WithCancel(context.Background())
+		defer cancelOperation()
+
+		future := m.GetAsync(operationCtx, "apple")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if _, _, err := future.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+
+		select {
+		case <-future.Done():
+			t.Fatal("the operation ended with the ctx of Get")
+		default:
+		}
+	})
+
+	t.Run("cancelling the operation ctx completes the future", func(t *testing.T) {
+
+		operationCtx, cancelOperation := context.WithCancel(context.Background())
+
+		future := m.GetAsync(operationCtx, "apple")
+		cancelOperation()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if _, found, err := future.Get(ctx); !errors.Is(err, context.Canceled) || found {
+			t.Fatalf("got %v, %v, want %v", found, err, context.Canceled)
+		}
+	})
+}
+
+func TestMapGetAllPutAll(t *testing.T) {
+
+	stub := newStubMap()
+	var failPartition atomic.Int32
+	failPartition.Store(-1)
+
+	client, _ := newStubClient(t, func(request *ClientMessage) []*ClientMessage {
+
+		if request.GetMessageType() == CLIENT_MAP_GET_ALL && request.GetPartitionId() == failPartition.Load() {
+			return []*ClientMessage{errorResponse(ERROR_ILLEGAL_STATE, "java.lang.IllegalStateException")}
+		}
+		return stub.handle(request)
+	})
+	ctx := context.Background()
+
+	m, err := GetMap[string, string](ctx, client, "colors")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []Entry[string, string]
+	var keys []string
+	for i := 0; i < 40; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		entries = append(entries, Entry[string, string]{Key: key, Value: fmt.Sprintf("value-%d", i)})
+		keys = append(keys, key, fmt.Sprintf("absent-%d", i))
+	}
+
+	// One request per partition, carrying exactly the keys of that partition
+	checkGrouping := func(t *testing.T, messageType uint16, keyCount int) {
+
+		requests := stub.received()
+		if len(requests) < 2 {
+			t.Fatalf("got %d requests, want one per partition", len(requests))
+		}
+
+		partitions := make(map[int32]bool)
+		count := 0
+		for _, request := range requests {
+			if request.messageType != messageType {
+				t.Fatalf("message type: got 0x%04x, want 0x%04x", request.messageType, messageType)
+			}
+			if partitions[request.partitionId] {
+				t.Fatalf("partition %d was sent more than one request", request.partitionId)
+			}
+			partitions[request.partitionId] = true
+			for _, key := range request.keys {
+				if partitionId := client.PartitionService().GetDataPartitionId(key); partitionId != request.partitionId {
+					t.Fatalf("key of partition %d sent to partition %d", partitionId, request.partitionId)
+				}
+			}
+			count += len(request.keys)
+		}
+		if count != keyCount {
+			t.Fatalf("got %d keys, want %d", count, keyCount)
+		}
+	}
+
+	t.Run("put all", func(t *testing.T) {
+
+		if err := m.PutAll(ctx, entries); err != nil {
+			t.Fatal(err)
+		}
+		checkGrouping(t, CLIENT_MAP_PUT_ALL, len(entries))
+	})
+
+	t.Run("get all", func(t *testing.T) {
+
+		result, err := m.GetAll(ctx, keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkGrouping(t, CLIENT_MAP_GET_ALL, len(keys))
+
+		merged := make(map[string]string)
+		for _, entry := range result {
+			merged[entry.Key] = entry.Value
+		}
+		if len(result) != len(entries) || len(merged) != len(entries) {
+			t.Fatalf("got %d entries, want %d", len(result), len(entries))
+		}
+		for _, entry := range entries {
+			if merged[entry.Key] != entry.Value {
+				t.Fatalf("%s: got %q, want %q", entry.Key, merged[entry.Key], entry.Value)
+			}
+		}
+	})
+
+	t.Run("get all fails with one partition", func(t *testing.T) {
+
+		keyData, _ := client.SerializationService().ToData(keys[0])
+		failPartition.Store(client.PartitionService().GetDataPartitionId(keyData))
+		defer failPartition.Store(-1)
+
+		if _, err := m.GetAll(ctx, keys); !errors.Is(err, ErrIllegalState) {
+			t.Fatalf("got %v, want %v", err, ErrIllegalState)
+		}
+		stub.received()
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+
+		if result, err := m.GetAll(ctx, nil); err != nil || len(result) != 0 {
+			t.Fatalf("got %v, %v", result, err)
+		}
+		if err := m.PutAll(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+		if requests := stub.received(); len(requests) != 0 {
+			t.Fatalf("got %d requests", len(requests))
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package hz
+
+func EncodeAddMembershipListenerRequest(localOnly bool) *ClientMessage {
+
+	message := CreateForEncode(BOOLEAN_SIZE_IN_BYTES)
+	message.SetMessageType(CLIENT_ADDMEMBERSHIPLISTENER)
+	message.AppendBool(localOnly)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+func decodeMember(message *ClientMessage) Member {
+
+	member := Member{}
+	member.Address = decodeAddress(message)
+	member.Uuid = *message.readString()
+	member.LiteMember = message.readBool()
+
+	attributes := message.readInt()
+	member.Attributes = make(map[string]string, attributes)
+	for i := int32(0); i < attributes; i++ {
+		key := message.readString()
+		member.Attributes[*key] = *message.readString()
+	}
+
+	return member
+}
+
+// EVENT_MEMBER: the member and MEMBER_ADDED or MEMBER_REMOVED
+func DecodeMemberEvent(message *ClientMessage) (Member, int32) {
+
+	member := decodeMember(message)
+	eventType := message.readInt()
+
+	return member, eventType
+}
+
+// EVENT_MEMBERLIST: the complete member list
+func DecodeMemberListEvent(message *ClientMessage) []Member {
+
+	count := message.readInt()
+	members := make([]Member, count)
+	for i := int32(0); i < count; i++ {
+		members[i] = decodeMember(message)
+	}
+
+	return members
+}
+
+// EVENT_MEMBERATTRIBUTECHANGE: member uuid, attribute key, MEMBER_ATTRIBUTE_PUT or MEMBER_ATTRIBUTE_REMOVE and the new value
+func DecodeMemberAttributeChangeEvent(message *ClientMessage) (string, string, int32, *string) {
+
+	uuid := message.readString()
+	key := message.readString()
+	operationType := message.readInt()
+
+	var value *string
+	if !message.readBool() {
+		value = message.readString()
+	}
+
+	return *uuid, *key, operationType, value
+}
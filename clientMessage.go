@@ -226,6 +226,15 @@ func (msg *ClientMessage) readInt() int32 {
 	return int
 }
 
+func (msg *ClientMessage) readInt64() int64 {
+
+	offset:= msg.readOffset()
+	long := int64(binary.LittleEndian.Uint64(msg.Buffer[offset:offset + INT64_SIZE_IN_BYTES]))
+	msg.readIndex += INT64_SIZE_IN_BYTES
+
+	return long
+}
+
 func (msg *ClientMessage) readByte() uint8 {
 
 	byte := byte(msg.Buffer[msg.readOffset()])
@@ -268,10 +277,86 @@ func (msg *ClientMessage) readByteArray() []byte {
 	return result
 }
 
+// A list of Data as an int count followed by each Data
+func (msg *ClientMessage) AppendDataList(list []Data) {
+
+	msg.AppendInt(len(list))
+	for _, data := range list {
+		msg.AppendByteArray(data)
+	}
+}
+
+// A nullable Data as the null flag followed by the Data when not null
+func (msg *ClientMessage) AppendNullableData(data Data) {
+
+	msg.AppendBool(data == nil)
+	if data != nil {
+		msg.AppendByteArray(data)
+	}
+}
+
+func (msg *ClientMessage) readData() Data {
+
+	return Data(msg.readByteArray())
+}
+
+// Null Data as nil
+func (msg *ClientMessage) readNullableData() Data {
+
+	if msg.readBool() {
+		return nil
+	}
+
+	return msg.readData()
+}
+
+func (msg *ClientMessage) readDataList() []Data {
+
+	list := make([]Data, msg.readInt())
+	for i := range list {
+		list[i] = msg.readData()
+	}
+
+	return list
+}
+
+// A serialized key and value
+type DataEntry struct {
+
+	Key   Data
+	Value Data
+}
+
+// A list of entries as an int count followed by the key and value Data of each
+func (msg *ClientMessage) AppendEntryList(list []DataEntry) {
+
+	msg.AppendInt(len(list))
+	for _, entry := range list {
+		msg.AppendByteArray(entry.Key)
+		msg.AppendByteArray(entry.Value)
+	}
+}
+
+func (msg *ClientMessage) readEntryList() []DataEntry {
+
+	list := make([]DataEntry, msg.readInt())
+	for i := range list {
+		list[i].Key = msg.readData()
+		list[i].Value = msg.readData()
+	}
+
+	return list
+}
+
 /*
 	Helpers
  */
 
+// More payload remains, used for response fields added in later protocol versions
+func (msg *ClientMessage) hasUnreadData() bool {
+	return msg.readOffset() < int(msg.GetFrameLength())
+}
+
 func (msg *ClientMessage) IsRetryable() bool {
 	return msg.isRetryable
 }
@@ -284,10 +369,105 @@ func (msg *ClientMessage) UpdateFrameLength() {
 	msg.SetFrameLength(int32(msg.writeIndex))
 }
 
+/*
+	Fragmentation
+ */
+
+// Split the message into frames of at most frameSize bytes, each frame carrying a copy of the header
+func (msg *ClientMessage) Fragment(frameSize int) []*ClientMessage {
+
+	frameLength := int(msg.GetFrameLength())
+	if frameSize <= HEADER_SIZE || frameLength <= frameSize {
+		return []*ClientMessage{msg}
+	}
+
+	dataOffset := int(msg.GetDataOffset())
+	payload := msg.Buffer[dataOffset:frameLength]
+	chunkSize := frameSize - HEADER_SIZE
+	otherFlags := msg.GetFlags() &^ BEGIN_END_FLAG
+
+	fragments := make([]*ClientMessage, 0, len(payload)/chunkSize+1)
+
+	for offset := 0; offset < len(payload); offset += chunkSize {
+
+		end := offset + chunkSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		fragment := new(ClientMessage)
+		fragment.Buffer = make([]byte, HEADER_SIZE+end-offset)
+		copy(fragment.Buffer, msg.Buffer[:HEADER_SIZE])
+		copy(fragment.Buffer[HEADER_SIZE:], payload[offset:end])
+		fragment.SetDataOffset(uint16(HEADER_SIZE))
+		fragment.SetFrameLength(int32(len(fragment.Buffer)))
+
+		flags := otherFlags
+		if offset == 0 {
+			flags |= BEGIN_FLAG
+		}
+		if end == len(payload) {
+			flags |= END_FLAG
+		}
+		fragment.SetFlags(flags)
+
+		fragments = append(fragments, fragment)
+	}
+
+	return fragments
+}
+
+// Append the payload of a continuation frame to a message started by a BEGIN frame
+func (msg *ClientMessage) Accumulate(fragment *ClientMessage) {
+
+	payload := fragment.Buffer[fragment.GetDataOffset():fragment.GetFrameLength()]
+	msg.Buffer = append(msg.Buffer[:msg.GetFrameLength()], payload...)
+	msg.SetFrameLength(int32(len(msg.Buffer)))
+	msg.SetFlags(msg.GetFlags() | fragment.HasFlags(END_FLAG))
+}
+
+func (msg *ClientMessage) IsComplete() bool {
+	return msg.HasFlags(END_FLAG) != 0
+}
+
 /*
 	Free methods
  */
 
+func CalculateSizeData(data Data) int {
+
+	return INT_SIZE_IN_BYTES + len(data)
+}
+
+func CalculateSizeNullableData(data Data) int {
+
+	if data == nil {
+		return BOOLEAN_SIZE_IN_BYTES
+	}
+
+	return BOOLEAN_SIZE_IN_BYTES + CalculateSizeData(data)
+}
+
+func CalculateSizeDataList(list []Data) int {
+
+	size := INT_SIZE_IN_BYTES
+	for _, data := range list {
+		size += CalculateSizeData(data)
+	}
+
+	return size
+}
+
+func CalculateSizeEntryList(list []DataEntry) int {
+
+	size := INT_SIZE_IN_BYTES
+	for _, entry := range list {
+		size += CalculateSizeData(entry.Key) + CalculateSizeData(entry.Value)
+	}
+
+	return size
+}
+
 func CalculateSizeStr(str *string) int {
 	return len(*str) + INT_SIZE_IN_BYTES
 }
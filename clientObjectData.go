@@ -0,0 +1,577 @@
+package hz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+/*
+Big endian streams as used by the java ObjectDataOutput and ObjectDataInput. Arrays are written as a 4 byte length,
+-1 for nil, followed by the elements. Strings use java writeUTF.
+*/
+
+type ObjectDataOutput struct {
+
+	buffer  []byte
+	service *SerializationService
+}
+
+func NewObjectDataOutput(service *SerializationService) *ObjectDataOutput {
+
+	output := new(ObjectDataOutput)
+	output.buffer = make([]byte, 0, 64)
+	output.service = service
+
+	return output
+}
+
+// The bytes written so far
+func (this *ObjectDataOutput) ToBuffer() []byte {
+
+	return this.buffer
+}
+
+// Offset of the next byte written, portable field positions are absolute within the buffer
+func (this *ObjectDataOutput) Position() int {
+
+	return len(this.buffer)
+}
+
+func (this *ObjectDataOutput) WriteZeroBytes(count int) {
+
+	for i := 0; i < count; i++ {
+		this.buffer = append(this.buffer, 0)
+	}
+}
+
+// Overwrite 4 bytes already written at position
+func (this *ObjectDataOutput) WriteInt32At(position int, v int32) {
+
+	binary.BigEndian.PutUint32(this.buffer[position:], uint32(v))
+}
+
+func (this *ObjectDataOutput) WriteUInt8(v byte) {
+
+	this.buffer = append(this.buffer, v)
+}
+
+func (this *ObjectDataOutput) WriteBool(v bool) {
+
+	if v {
+		this.WriteUInt8(1)
+	} else {
+		this.WriteUInt8(0)
+	}
+}
+
+func (this *ObjectDataOutput) WriteUInt16(v uint16) {
+
+	this.buffer = append(this.buffer, byte(v>>8), byte(v))
+}
+
+func (this *ObjectDataOutput) WriteInt16(v int16) {
+
+	this.WriteUInt16(uint16(v))
+}
+
+func (this *ObjectDataOutput) WriteInt32(v int32) {
+
+	this.buffer = append(this.buffer, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(this.buffer[len(this.buffer)-INT_SIZE_IN_BYTES:], uint32(v))
+}
+
+func (this *ObjectDataOutput) WriteInt64(v int64) {
+
+	this.buffer = append(this.buffer, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(this.buffer[len(this.buffer)-LONG_SIZE_IN_BYTES:], uint64(v))
+}
+
+func (this *ObjectDataOutput) WriteFloat32(v float32) {
+
+	this.WriteInt32(int32(math.Float32bits(v)))
+}
+
+func (this *ObjectDataOutput) WriteFloat64(v float64) {
+
+	this.WriteInt64(int64(math.Float64bits(v)))
+}
+
+func (this *ObjectDataOutput) WriteUTF(v string) {
+
+	this.buffer = appendUTF(this.buffer, v)
+}
+
+func (this *ObjectDataOutput) WriteByteArray(v []byte) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	this.buffer = append(this.buffer, v...)
+}
+
+func (this *ObjectDataOutput) WriteBoolArray(v []bool) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	for _, e := range v {
+		this.WriteBool(e)
+	}
+}
+
+func (this *ObjectDataOutput) WriteUInt16Array(v []uint16) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	for _, e := range v {
+		this.WriteUInt16(e)
+	}
+}
+
+func (this *ObjectDataOutput) WriteInt16Array(v []int16) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	for _, e := range v {
+		this.WriteInt16(e)
+	}
+}
+
+func (this *ObjectDataOutput) WriteInt32Array(v []int32) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	for _, e := range v {
+		this.WriteInt32(e)
+	}
+}
+
+func (this *ObjectDataOutput) WriteInt64Array(v []int64) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	for _, e := range v {
+		this.WriteInt64(e)
+	}
+}
+
+func (this *ObjectDataOutput) WriteFloat32Array(v []float32) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	for _, e := range v {
+		this.WriteFloat32(e)
+	}
+}
+
+func (this *ObjectDataOutput) WriteFloat64Array(v []float64) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	for _, e := range v {
+		this.WriteFloat64(e)
+	}
+}
+
+func (this *ObjectDataOutput) WriteUTFArray(v []string) {
+
+	if v == nil {
+		this.WriteInt32(-1)
+		return
+	}
+
+	this.WriteInt32(int32(len(v)))
+	for _, e := range v {
+		this.WriteUTF(e)
+	}
+}
+
+// Write Data as a length prefixed byte array, nil as -1
+func (this *ObjectDataOutput) WriteData(data Data) {
+
+	this.WriteByteArray(data)
+}
+
+// Write any value the SerializationService can serialize, preceded by its serializer type id
+func (this *ObjectDataOutput) WriteObject(value interface{}) error {
+
+	if value == nil {
+		this.WriteInt32(TYPE_NULL)
+		return nil
+	}
+
+	serializer := this.service.serializerFor(value)
+	if serializer == nil {
+		return fmt.Errorf("%w for type: %T", ErrSerializerNotFound, value)
+	}
+
+	this.WriteInt32(serializer.TypeId())
+
+	return serializer.write(this, value)
+}
+
+// Reads are sticky on error, once a read fails all further reads return zero values and Error reports the failure
+type ObjectDataInput struct {
+
+	buffer   []byte
+	position int
+	service  *SerializationService
+	err      error
+}
+
+func NewObjectDataInput(buffer []byte, service *SerializationService) *ObjectDataInput {
+
+	input := new(ObjectDataInput)
+	input.buffer = buffer
+	input.service = service
+
+	return input
+}
+
+// The first read failure, if any
+func (this *ObjectDataInput) Error() error {
+
+	return this.err
+}
+
+// Record a failure, ReadData implementations can use this to reject content
+func (this *ObjectDataInput) SetError(err error) {
+
+	if this.err == nil {
+		this.err = err
+	}
+}
+
+func (this *ObjectDataInput) Position() int {
+
+	return this.position
+}
+
+func (this *ObjectDataInput) SetPosition(position int) {
+
+	if this.err != nil {
+		return
+	}
+
+	if position < 0 || position > len(this.buffer) {
+		this.err = fmt.Errorf("%w: position %d outside %d bytes", ErrInvalidData, position, len(this.buffer))
+		return
+	}
+
+	this.position = position
+}
+
+// Read 4 bytes at position without moving the current position
+func (this *ObjectDataInput) ReadInt32At(position int) int32 {
+
+	current := this.position
+
+	this.SetPosition(position)
+	v := this.ReadInt32()
+	this.position = current
+
+	return v
+}
+
+func (this *ObjectDataInput) Available() int {
+
+	return len(this.buffer) - this.position
+}
+
+func (this *ObjectDataInput) next(size int) []byte {
+
+	if this.err != nil {
+		return nil
+	}
+
+	if size < 0 || this.Available() < size {
+		this.err = fmt.Errorf("%w: %d bytes required at position %d, %d available", ErrInvalidData, size, this.position, this.Available())
+		return nil
+	}
+
+	bytes := this.buffer[this.position : this.position+size]
+	this.position += size
+
+	return bytes
+}
+
+func (this *ObjectDataInput) ReadUInt8() byte {
+
+	if bytes := this.next(BYTE_SIZE_IN_BYTES); bytes != nil {
+		return bytes[0]
+	}
+
+	return 0
+}
+
+func (this *ObjectDataInput) ReadBool() bool {
+
+	return this.ReadUInt8() != 0
+}
+
+func (this *ObjectDataInput) ReadUInt16() uint16 {
+
+	if bytes := this.next(SHORT_SIZE_IN_BYTES); bytes != nil {
+		return binary.BigEndian.Uint16(bytes)
+	}
+
+	return 0
+}
+
+func (this *ObjectDataInput) ReadInt16() int16 {
+
+	return int16(this.ReadUInt16())
+}
+
+func (this *ObjectDataInput) ReadInt32() int32 {
+
+	if bytes := this.next(INT_SIZE_IN_BYTES); bytes != nil {
+		return int32(binary.BigEndian.Uint32(bytes))
+	}
+
+	return 0
+}
+
+func (this *ObjectDataInput) ReadInt64() int64 {
+
+	if bytes := this.next(LONG_SIZE_IN_BYTES); bytes != nil {
+		return int64(binary.BigEndian.Uint64(bytes))
+	}
+
+	return 0
+}
+
+func (this *ObjectDataInput) ReadFloat32() float32 {
+
+	return math.Float32frombits(uint32(this.ReadInt32()))
+}
+
+func (this *ObjectDataInput) ReadFloat64() float64 {
+
+	return math.Float64frombits(uint64(this.ReadInt64()))
+}
+
+func (this *ObjectDataInput) ReadUTF() string {
+
+	if this.err != nil {
+		return ""
+	}
+
+	str, size, err := readUTF(this.buffer[this.position:])
+	if err != nil {
+		this.err = err
+		return ""
+	}
+	this.position += size
+
+	return str
+}
+
+// Array length, -1 for nil, guarding against lengths the remaining bytes cannot hold
+func (this *ObjectDataInput) readArrayLength(elementSize int) int32 {
+
+	length := this.ReadInt32()
+	if length > 0 && this.err == nil && this.Available() < int(length)*elementSize {
+		this.err = fmt.Errorf("%w: array of %d elements at position %d, %d bytes available", ErrInvalidData, length, this.position, this.Available())
+		return -1
+	}
+
+	return length
+}
+
+func (this *ObjectDataInput) ReadByteArray() []byte {
+
+	length := this.readArrayLength(BYTE_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]byte, length)
+	copy(v, this.next(int(length)))
+
+	return v
+}
+
+func (this *ObjectDataInput) ReadBoolArray() []bool {
+
+	length := this.readArrayLength(BOOLEAN_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]bool, length)
+	for i := range v {
+		v[i] = this.ReadBool()
+	}
+
+	return v
+}
+
+func (this *ObjectDataInput) ReadUInt16Array() []uint16 {
+
+	length := this.readArrayLength(SHORT_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]uint16, length)
+	for i := range v {
+		v[i] = this.ReadUInt16()
+	}
+
+	return v
+}
+
+func (this *ObjectDataInput) ReadInt16Array() []int16 {
+
+	length := this.readArrayLength(SHORT_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]int16, length)
+	for i := range v {
+		v[i] = this.ReadInt16()
+	}
+
+	return v
+}
+
+func (this *ObjectDataInput) ReadInt32Array() []int32 {
+
+	length := this.readArrayLength(INT_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]int32, length)
+	for i := range v {
+		v[i] = this.ReadInt32()
+	}
+
+	return v
+}
+
+func (this *ObjectDataInput) ReadInt64Array() []int64 {
+
+	length := this.readArrayLength(LONG_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]int64, length)
+	for i := range v {
+		v[i] = this.ReadInt64()
+	}
+
+	return v
+}
+
+func (this *ObjectDataInput) ReadFloat32Array() []float32 {
+
+	length := this.readArrayLength(INT_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]float32, length)
+	for i := range v {
+		v[i] = this.ReadFloat32()
+	}
+
+	return v
+}
+
+func (this *ObjectDataInput) ReadFloat64Array() []float64 {
+
+	length := this.readArrayLength(LONG_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]float64, length)
+	for i := range v {
+		v[i] = this.ReadFloat64()
+	}
+
+	return v
+}
+
+func (this *ObjectDataInput) ReadUTFArray() []string {
+
+	length := this.readArrayLength(INT_SIZE_IN_BYTES)
+	if length < 0 || this.err != nil {
+		return nil
+	}
+
+	v := make([]string, length)
+	for i := range v {
+		v[i] = this.ReadUTF()
+	}
+
+	return v
+}
+
+// Read Data written by WriteData, nil when written as nil
+func (this *ObjectDataInput) ReadData() Data {
+
+	if bytes := this.ReadByteArray(); bytes != nil {
+		return Data(bytes)
+	}
+
+	return nil
+}
+
+// Read a value written by WriteObject
+func (this *ObjectDataInput) ReadObject() interface{} {
+
+	typeId := this.ReadInt32()
+	if this.err != nil || typeId == TYPE_NULL {
+		return nil
+	}
+
+	serializer, ok := this.service.byTypeId[typeId]
+	if !ok {
+		this.err = fmt.Errorf("%w for type id: %d", ErrSerializerNotFound, typeId)
+		return nil
+	}
+
+	value, err := serializer.read(this)
+	if err != nil {
+		this.SetError(err)
+		return nil
+	}
+
+	return value
+}
@@ -0,0 +1,419 @@
+package hz
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+/* Names of the java IterationType, the part of each entry a paged query returns */
+const (
+
+	ITERATION_TYPE_KEY = "KEY"
+	ITERATION_TYPE_VALUE = "VALUE"
+	ITERATION_TYPE_ENTRY = "ENTRY"
+)
+
+/*
+Returns the results of a map query one page at a time. Each member returns the page after the anchor, the last entry of the
+previous page, and the client sorts and merges these to select the page, keeping the anchors in the predicate for the next query.
+Results are ordered by key for key and entry queries and by value for value queries unless a comparator is set.
+Not safe for concurrent use.
+*/
+type PagingPredicate struct {
+
+	predicate Predicate
+
+	// Serialized for the cluster, compare orders the results the same way on the client
+	comparator interface{}
+	compare    func(a Entry[any, any], b Entry[any, any]) int
+
+	page          int32
+	pageSize      int32
+	iterationType string
+	anchors       []pagingAnchor
+}
+
+// The last entry of a page
+type pagingAnchor struct {
+
+	page  int32
+	entry Entry[any, any]
+}
+
+// Page through the entries matching predicate, or all entries when predicate is nil
+func NewPagingPredicate(predicate Predicate, pageSize int32) (*PagingPredicate, error) {
+
+	if pageSize <= 0 {
+		return nil, errors.New(fmt.Sprintf("Page size must be greater than 0, got: %d", pageSize))
+	}
+
+	if _, ok := predicate.(*PagingPredicate); ok {
+		return nil, errors.New("Nested paging predicates are not supported")
+	}
+
+	return &PagingPredicate{predicate: predicate, pageSize: pageSize, iterationType: ITERATION_TYPE_ENTRY}, nil
+}
+
+/*
+Order results with comparator, an object serialized as a java Comparator<Map.Entry> such as an IdentifiedDataSerializable,
+and compare, its Go equivalent used to sort the results returned by the members. The two must order entries the same way.
+Resets the predicate as the anchors no longer apply
+*/
+func (this *PagingPredicate) SetComparator(comparator interface{}, compare func(a Entry[any, any], b Entry[any, any]) int) {
+
+	this.comparator = comparator
+	this.compare = compare
+	this.Reset()
+}
+
+func (this *PagingPredicate) FactoryID() int32 { return PREDICATE_FACTORY_ID }
+func (this *PagingPredicate) ClassID() int32   { return PAGING_PREDICATE }
+
+// The current page, from 0
+func (this *PagingPredicate) Page() int32 {
+
+	return this.page
+}
+
+func (this *PagingPredicate) PageSize() int32 {
+
+	return this.pageSize
+}
+
+// Go to any page, the query starts from the nearest earlier page already fetched
+func (this *PagingPredicate) SetPage(page int32) {
+
+	if page < 0 {
+		page = 0
+	}
+
+	this.page = page
+}
+
+func (this *PagingPredicate) NextPage() {
+
+	this.page++
+}
+
+// Stays on page 0
+func (this *PagingPredicate) PreviousPage() {
+
+	if this.page > 0 {
+		this.page--
+	}
+}
+
+// Back to page 0, forgetting the anchors
+func (this *PagingPredicate) Reset() {
+
+	this.page = 0
+	this.anchors = nil
+}
+
+/*
+Payload: the predicate and comparator as objects, the page, page size and iteration type name, then the anchor count followed
+by the page, key and value of each anchor
+*/
+func (this *PagingPredicate) WriteData(output *ObjectDataOutput) error {
+
+	if err := output.WriteObject(this.predicate); err != nil {
+		return err
+	}
+	if err := output.WriteObject(this.comparator); err != nil {
+		return err
+	}
+
+	output.WriteInt32(this.page)
+	output.WriteInt32(this.pageSize)
+	output.WriteUTF(this.iterationType)
+
+	output.WriteInt32(int32(len(this.anchors)))
+	for _, anchor := range this.anchors {
+		output.WriteInt32(anchor.page)
+		if err := output.WriteObject(anchor.entry.Key); err != nil {
+			return err
+		}
+		if err := output.WriteObject(anchor.entry.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (this *PagingPredicate) ReadData(input *ObjectDataInput) error {
+
+	var err error
+	if this.predicate, err = ReadPredicate(input); err != nil {
+		return err
+	}
+
+	this.comparator = input.ReadObject()
+	this.page = input.ReadInt32()
+	this.pageSize = input.ReadInt32()
+	this.iterationType = input.ReadUTF()
+
+	count := input.ReadInt32()
+	if input.Error() != nil {
+		return input.Error()
+	}
+	if count < 0 || int(count) > input.Available() {
+		return fmt.Errorf("%w: paging predicate anchor count %d", ErrInvalidData, count)
+	}
+
+	this.anchors = make([]pagingAnchor, count)
+	for i := range this.anchors {
+		this.anchors[i].page = input.ReadInt32()
+		this.anchors[i].entry.Key = input.ReadObject()
+		this.anchors[i].entry.Value = input.ReadObject()
+	}
+
+	return input.Error()
+}
+
+// The anchor of the nearest page before the current one, page -1 when there is none
+func (this *PagingPredicate) nearestAnchor() pagingAnchor {
+
+	count := int32(len(this.anchors))
+	if this.page == 0 || count == 0 {
+		return pagingAnchor{page: -1}
+	}
+
+	if this.page < count {
+		return this.anchors[this.page-1]
+	}
+
+	return this.anchors[count-1]
+}
+
+func (this *PagingPredicate) setAnchor(page int32, entry Entry[any, any]) error {
+
+	count := int32(len(this.anchors))
+	anchor := pagingAnchor{page: page, entry: entry}
+
+	if page < count {
+		this.anchors[page] = anchor
+	} else if page == count {
+		this.anchors = append(this.anchors, anchor)
+	} else {
+		return errors.New(fmt.Sprintf("Paging anchor index is not correct, expected: %d found: %d", page, count))
+	}
+
+	return nil
+}
+
+// Sort the merged member results and select the current page, recording the anchors of the pages passed on the way as the java client does
+func (this *PagingPredicate) selectPage(entries []Entry[any, any]) ([]Entry[any, any], error) {
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	if this.comparator != nil && this.compare == nil {
+		return nil, errors.New("Paging predicate comparator is set without a compare function")
+	}
+
+	var sortErr error
+	sort.SliceStable(entries, func(i int, j int) bool {
+		result, err := this.compareEntries(entries[i], entries[j])
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return result < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	nearestPage := this.nearestAnchor().page
+	size := int32(len(entries))
+
+	begin := this.pageSize * (this.page - nearestPage - 1)
+	if begin > size {
+		return nil, nil
+	}
+
+	end := begin + this.pageSize
+	if end > size {
+		end = size
+	}
+
+	for i := this.pageSize; i <= size && nearestPage < this.page; i += this.pageSize {
+		nearestPage++
+		if err := this.setAnchor(nearestPage, entries[i-1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries[begin:end], nil
+}
+
+// As the java SortingUtil, without a comparator keys are compared for key and entry queries and values for value queries.
+// Ties are broken by the java hashCode of the keys, entries with keys of other types keep the order returned by the members
+func (this *PagingPredicate) compareEntries(a Entry[any, any], b Entry[any, any]) (int, error) {
+
+	if this.compare != nil {
+		if result := this.compare(a, b); result != 0 {
+			return result, nil
+		}
+	} else {
+		var result int
+		var err error
+		if this.iterationType == ITERATION_TYPE_VALUE {
+			result, err = compareObjects(a.Value, b.Value)
+		} else {
+			result, err = compareObjects(a.Key, b.Key)
+		}
+		if err != nil || result != 0 {
+			return result, err
+		}
+	}
+
+	hashA, okA := javaHashCode(a.Key)
+	hashB, okB := javaHashCode(b.Key)
+	if !okA || !okB {
+		return 0, nil
+	}
+
+	return cmp.Compare(hashA, hashB), nil
+}
+
+// Natural ordering of the values java compares as Comparable
+func compareObjects(a interface{}, b interface{}) (int, error) {
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return compareUTF16(x, y), nil
+		}
+	case int:
+		if y, ok := b.(int); ok {
+			return cmp.Compare(x, y), nil
+		}
+	case int64:
+		if y, ok := b.(int64); ok {
+			return cmp.Compare(x, y), nil
+		}
+	case int32:
+		if y, ok := b.(int32); ok {
+			return cmp.Compare(x, y), nil
+		}
+	case int16:
+		if y, ok := b.(int16); ok {
+			return cmp.Compare(x, y), nil
+		}
+	case uint16:
+		if y, ok := b.(uint16); ok {
+			return cmp.Compare(x, y), nil
+		}
+	case uint8:
+		if y, ok := b.(uint8); ok {
+			return cmp.Compare(int8(x), int8(y)), nil
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y), nil
+		}
+	case float32:
+		if y, ok := b.(float32); ok {
+			return cmp.Compare(x, y), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			if x == y {
+				return 0, nil
+			}
+			if x {
+				return 1, nil
+			}
+			return -1, nil
+		}
+	}
+
+	return 0, errors.New(fmt.Sprintf("Paging predicate cannot compare %T with %T, set a comparator", a, b))
+}
+
+// Compare as java String.compareTo, by UTF-16 code units. This differs from comparing the UTF-8 bytes only when a character
+// above U+FFFF, encoded as a surrogate pair, meets one from U+E000 to U+FFFF
+func compareUTF16(a string, b string) int {
+
+	for a != "" && b != "" {
+		x, xSize := utf8.DecodeRuneInString(a)
+		y, ySize := utf8.DecodeRuneInString(b)
+		if x != y {
+			if result := cmp.Compare(firstUTF16Unit(x), firstUTF16Unit(y)); result != 0 {
+				return result
+			}
+			// Same high surrogate, the low surrogates are in code point order
+			return cmp.Compare(x, y)
+		}
+		a, b = a[xSize:], b[ySize:]
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+// The character itself, or the high surrogate of a supplementary character
+func firstUTF16Unit(r rune) rune {
+
+	if r >= 0x10000 {
+		high, _ := utf16.EncodeRune(r)
+		return high
+	}
+
+	return r
+}
+
+// The java hashCode of the types with a builtin serializer, false for other types
+func javaHashCode(value interface{}) (int32, bool) {
+
+	switch x := value.(type) {
+	case string:
+		var hash int32
+		for _, r := range x {
+			if r >= 0x10000 {
+				high, low := utf16.EncodeRune(r)
+				hash = 31*(31*hash+high) + low
+			} else {
+				hash = 31*hash + r
+			}
+		}
+		return hash, true
+	case int32:
+		return x, true
+	case int64:
+		return int32(x ^ int64(uint64(x)>>32)), true
+	case int:
+		return javaHashCode(int64(x))
+	case int16:
+		return int32(x), true
+	case uint16:
+		return int32(x), true
+	case uint8:
+		return int32(int8(x)), true
+	case bool:
+		if x {
+			return 1231, true
+		}
+		return 1237, true
+	case float32:
+		// floatToIntBits, every NaN has the same hash
+		if x != x {
+			return 0x7fc00000, true
+		}
+		return int32(math.Float32bits(x)), true
+	case float64:
+		bits := math.Float64bits(x)
+		if x != x {
+			bits = 0x7ff8000000000000
+		}
+		return int32(bits ^ bits>>32), true
+	}
+
+	return 0, false
+}
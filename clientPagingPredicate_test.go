@@ -0,0 +1,288 @@
+package hz
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCompareUTF16(t *testing.T) {
+
+	tests := []struct {
+		a, b   string
+		result int
+	}{
+		{"", "", 0},
+		{"", "a", -1},
+		{"a", "b", -1},
+		{"ab", "a", 1},
+		{"\u00e9", "z", 1},
+		// U+FFFF and U+E000 are above the high surrogate of U+1F600 though their UTF-8 bytes are below
+		{"\uffff", "\U0001f600", 1},
+		{"\ue000", "\U0001f600", 1},
+		{"\U0001f600", "\U0001f601", -1},
+		{"\U00010000", "\U0001f600", -1},
+		{"\U0001f600a", "\U0001f600", 1},
+	}
+
+	for _, test := range tests {
+		if result := compareUTF16(test.a, test.b); result != test.result {
+			t.Errorf("%q, %q: got %d, want %d", test.a, test.b, result, test.result)
+		}
+		if result := compareUTF16(test.b, test.a); result != -test.result {
+			t.Errorf("%q, %q: got %d, want %d", test.b, test.a, result, -test.result)
+		}
+	}
+}
+
+// Expected values from the java hashCode of the boxed types
+func TestJavaHashCode(t *testing.T) {
+
+	tests := []struct {
+		value interface{}
+		hash  int32
+	}{
+		{"", 0},
+		{"hello", 99162322},
+		{"Aa", 2112},
+		{"BB", 2112},
+		{"\U0001f600", 1772899},
+		{int32(-7), -7},
+		{int64(-1), 0},
+		{int64(1) << 32, 1},
+		{5, 5},
+		{int16(-3), -3},
+		{uint16('A'), 65},
+		{uint8(0xff), -1},
+		{true, 1231},
+		{false, 1237},
+		{float32(1), 1065353216},
+		{float32(math.NaN()), 2143289344},
+		{1.0, 1072693248},
+		{math.NaN(), 2146959360},
+	}
+
+	for _, test := range tests {
+		if hash, ok := javaHashCode(test.value); !ok || hash != test.hash {
+			t.Errorf("%T %v: got %d, %v, want %d", test.value, test.value, hash, ok, test.hash)
+		}
+	}
+
+	if _, ok := javaHashCode(struct{}{}); ok {
+		t.Error("hash of an unknown type")
+	}
+}
+
+func TestPagingPredicateOrder(t *testing.T) {
+
+	// "b" sorts before "aa" by hashCode, 98 against 3104
+	entries := func() []Entry[any, any] {
+		return []Entry[any, any]{
+			{Key: "aa", Value: int32(1)},
+			{Key: "c", Value: int32(0)},
+			{Key: "b", Value: int32(1)},
+		}
+	}
+
+	keys := func(entries []Entry[any, any]) []interface{} {
+		var keys []interface{}
+		for _, entry := range entries {
+			keys = append(keys, entry.Key)
+		}
+		return keys
+	}
+
+	t.Run("keys", func(t *testing.T) {
+
+		predicate, _ := NewPagingPredicate(nil, 10)
+		page, err := predicate.selectPage(entries())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := keys(page), []interface{}{"aa", "b", "c"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("equal values by key hash", func(t *testing.T) {
+
+		predicate, _ := NewPagingPredicate(nil, 10)
+		predicate.iterationType = ITERATION_TYPE_VALUE
+		page, err := predicate.selectPage(entries())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := keys(page), []interface{}{"c", "b", "aa"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("comparator ties by key hash", func(t *testing.T) {
+
+		predicate, _ := NewPagingPredicate(nil, 10)
+		predicate.SetComparator("comparator", func(a Entry[any, any], b Entry[any, any]) int {
+			return 0
+		})
+		page, err := predicate.selectPage(entries())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := keys(page), []interface{}{"b", "c", "aa"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("comparator ties without a key hash keep the member order", func(t *testing.T) {
+
+		type key struct{ id int }
+
+		predicate, _ := NewPagingPredicate(nil, 10)
+		predicate.SetComparator("comparator", func(a Entry[any, any], b Entry[any, any]) int {
+			return 0
+		})
+		page, err := predicate.selectPage([]Entry[any, any]{{Key: key{2}}, {Key: key{0}}, {Key: key{1}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := keys(page), []interface{}{key{2}, key{0}, key{1}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("comparator without compare function", func(t *testing.T) {
+
+		predicate, _ := NewPagingPredicate(nil, 10)
+		predicate.SetComparator("comparator", nil)
+		if _, err := predicate.selectPage(entries()); err == nil {
+			t.Fatal("no error for a comparator without a compare function")
+		}
+	})
+
+	t.Run("uncomparable keys", func(t *testing.T) {
+
+		predicate, _ := NewPagingPredicate(nil, 10)
+		if _, err := predicate.selectPage([]Entry[any, any]{{Key: "a"}, {Key: int32(1)}}); err == nil {
+			t.Fatal("no error comparing a string with an int32")
+		}
+	})
+}
+
+// Two members holding the even and odd keys from 0 to 24, each returning as the java member does the entries after the
+// nearest anchor for the pages up to the current one
+func pagingMembers(predicate *PagingPredicate) []Entry[any, any] {
+
+	anchor := predicate.nearestAnchor()
+	limit := int(predicate.pageSize * (predicate.page - anchor.page))
+
+	var entries []Entry[any, any]
+	for member := 0; member < 2; member++ {
+		count := 0
+		for key := int32(member); key < 25 && count < limit; key += 2 {
+			if anchor.page >= 0 && key <= anchor.entry.Key.(int32) {
+				continue
+			}
+			entries = append(entries, Entry[any, any]{Key: key, Value: fmt.Sprintf("value-%d", key)})
+			count++
+		}
+	}
+
+	// The members answer in any order
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries
+}
+
+func TestPagingPredicatePages(t *testing.T) {
+
+	predicate, _ := NewPagingPredicate(nil, 10)
+
+	page := func(t *testing.T, first int32, last int32, anchors ...int32) {
+
+		entries, err := predicate.selectPage(pagingMembers(predicate))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var keys []int32
+		for _, entry := range entries {
+			keys = append(keys, entry.Key.(int32))
+		}
+		var want []int32
+		for key := first; key <= last; key++ {
+			want = append(want, key)
+		}
+		if !reflect.DeepEqual(keys, want) {
+			t.Fatalf("page %d: got %v, want %v", predicate.Page(), keys, want)
+		}
+
+		var anchorKeys []int32
+		for i, anchor := range predicate.anchors {
+			if anchor.page != int32(i) {
+				t.Fatalf("anchor %d is for page %d", i, anchor.page)
+			}
+			anchorKeys = append(anchorKeys, anchor.entry.Key.(int32))
+		}
+		if !reflect.DeepEqual(anchorKeys, anchors) {
+			t.Fatalf("anchors: got %v, want %v", anchorKeys, anchors)
+		}
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		page(t, 0, 9, 9)
+	})
+
+	t.Run("next page", func(t *testing.T) {
+		predicate.NextPage()
+		page(t, 10, 19, 9, 19)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		predicate.NextPage()
+		page(t, 20, 24, 9, 19)
+	})
+
+	t.Run("past the last page", func(t *testing.T) {
+		predicate.NextPage()
+		page(t, 0, -1, 9, 19)
+	})
+
+	t.Run("previous page", func(t *testing.T) {
+		predicate.PreviousPage()
+		predicate.PreviousPage()
+		page(t, 10, 19, 9, 19)
+	})
+
+	t.Run("previous page stays on page 0", func(t *testing.T) {
+		predicate.PreviousPage()
+		predicate.PreviousPage()
+		if predicate.Page() != 0 {
+			t.Fatalf("got page %d", predicate.Page())
+		}
+		page(t, 0, 9, 9, 19)
+	})
+
+	t.Run("anchors are serialized", func(t *testing.T) {
+
+		service, _ := NewSerializationService(nil)
+		data, err := service.ToData(predicate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		object, err := service.ToObject(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(object.(*PagingPredicate).anchors, predicate.anchors) {
+			t.Fatalf("got %v, want %v", object.(*PagingPredicate).anchors, predicate.anchors)
+		}
+	})
+
+	t.Run("jump to a page records the anchors on the way", func(t *testing.T) {
+		predicate.Reset()
+		predicate.SetPage(2)
+		page(t, 20, 24, 9, 19)
+	})
+}
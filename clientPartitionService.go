@@ -0,0 +1,207 @@
+package hz
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+
+	DEFAULT_PARTITION_REFRESH_INTERVAL = 10 * time.Second
+)
+
+// The partition id to owner member table, refreshed periodically and whenever the cluster reports a partition change
+type PartitionService struct {
+
+	invocationService *InvocationService
+
+	mutex                 sync.RWMutex
+	owners                map[int32]Address
+	partitionCount        int32
+	partitionStateVersion int32
+
+	registrationId string
+	stopOnce       *sync.Once
+	stop           chan struct{}
+
+	Logger ILogging
+}
+
+func newPartitionService(invocationService *InvocationService) *PartitionService {
+
+	service := new(PartitionService)
+	service.invocationService = invocationService
+	service.owners = make(map[int32]Address)
+	service.partitionStateVersion = -1
+	service.stopOnce = &sync.Once{}
+	service.stop = make(chan struct{})
+	service.Logger = invocationService.Logger
+
+	return service
+}
+
+// Load the partition table, listen for partition changes on the owner connection and refresh every refreshInterval until Shutdown
+func (this *PartitionService) Start(ctx context.Context, refreshInterval time.Duration) error {
+
+	if err := this.Refresh(ctx); err != nil {
+		return err
+	}
+
+	if err := this.addPartitionListener(ctx); err != nil {
+		// Older members do not support partition events, the periodic refresh still applies
+		this.Logger.Warn("Failed to add partition listener: %v", err)
+	}
+
+	ticker := time.NewTicker(refreshInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.stop:
+				return
+			case <-ticker.C:
+				refreshCtx, cancel := context.WithTimeout(context.Background(), refreshInterval)
+				if err := this.Refresh(refreshCtx); err != nil {
+					this.Logger.Warn("Periodic partition table refresh failed: %v", err)
+				}
+				cancel()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (this *PartitionService) Shutdown() {
+
+	this.stopOnce.Do(func() {
+		close(this.stop)
+		if this.registrationId != "" {
+			this.invocationService.listenerService.DeregisterListener(context.Background(), this.registrationId, nil)
+		}
+	})
+}
+
+// Fetch the partition table from the owner connection
+func (this *PartitionService) Refresh(ctx context.Context) error {
+
+	connection, err := this.invocationService.ownerConnection()
+	if err != nil {
+		return err
+	}
+
+	response, err := this.invocationService.InvokeOnConnectionWithContext(ctx, connection, encodePartitionRequest())
+	if err != nil {
+		return err
+	}
+
+	if err := checkResponse(response, RESPONSE_PARTITIONS); err != nil {
+		return err
+	}
+
+	this.update(DecodePartitionsResponse(response))
+
+	return nil
+}
+
+func (this *PartitionService) addPartitionListener(ctx context.Context) error {
+
+	id, cb, err := this.invocationService.listenerService.RegisterListener(ctx, encodeAddPartitionListenerRequest, RESPONSE_VOID)
+	if err != nil {
+		return err
+	}
+
+	this.registrationId = id
+
+	go func() {
+		for {
+			select {
+			case <-this.stop:
+				return
+			case event := <-cb.NotifyChannel:
+				if event.GetMessageType() == EVENT_PARTITIONS {
+					this.Logger.Trace("Received partitions event")
+					this.update(DecodePartitionsResponse(event))
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Replace the table unless the cluster reports an older partition state than the one held
+func (this *PartitionService) update(members []MemberPartitions, partitionStateVersion int32) {
+
+	owners := make(map[int32]Address)
+	for _, member := range members {
+		for _, partitionId := range member.PartitionIds {
+			owners[partitionId] = member.Address
+		}
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if partitionStateVersion >= 0 && partitionStateVersion < this.partitionStateVersion {
+		this.Logger.Trace("Ignoring stale partition table, version: %d, current: %d", partitionStateVersion, this.partitionStateVersion)
+		return
+	}
+
+	this.owners = owners
+	this.partitionStateVersion = partitionStateVersion
+	if len(owners) > 0 {
+		this.partitionCount = int32(len(owners))
+	}
+
+	this.Logger.Trace("Partition table updated, partition count: %d, version: %d", this.partitionCount, partitionStateVersion)
+}
+
+// Accept any partition table again, the state version is per owner member and starts over after a reconnect
+func (this *PartitionService) resetStateVersion() {
+
+	this.mutex.Lock()
+	this.partitionStateVersion = -1
+	this.mutex.Unlock()
+}
+
+func (this *PartitionService) PartitionCount() int32 {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	return this.partitionCount
+}
+
+// The owner of the partition, nil when not known
+func (this *PartitionService) GetPartitionOwner(partitionId int32) *Address {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	address, ok := this.owners[partitionId]
+	if !ok {
+		return nil
+	}
+
+	return &address
+}
+
+// The partition id for a serialized key, -1 until the partition table has been loaded
+func (this *PartitionService) GetPartitionId(key []byte) int32 {
+
+	partitionCount := this.PartitionCount()
+	if partitionCount == 0 {
+		return -1
+	}
+
+	return partitionIdForKey(key, partitionCount)
+}
+
+// The partition id for Data used as a key, honouring an explicit partition hash, -1 until the partition table has been loaded
+func (this *PartitionService) GetDataPartitionId(data Data) int32 {
+
+	return data.PartitionId(this.PartitionCount())
+}
@@ -0,0 +1,31 @@
+package hz
+
+import (
+	"testing"
+)
+
+func TestPartitionServiceStateVersion(t *testing.T) {
+
+	service := newPartitionService(&InvocationService{Logger: testLogger{t}})
+
+	first := Address{Host: "127.0.0.1", Port: 5701}
+	second := Address{Host: "127.0.0.1", Port: 5702}
+
+	service.update([]MemberPartitions{{Address: first, PartitionIds: []int32{0, 1}}}, 5)
+	service.update([]MemberPartitions{{Address: second, PartitionIds: []int32{0, 1}}}, 4)
+
+	if owner := service.GetPartitionOwner(0); owner == nil || *owner != first {
+		t.Fatalf("stale table applied, owner: %v", owner)
+	}
+
+	// A new owner member counts its partition state from the start
+	service.resetStateVersion()
+	service.update([]MemberPartitions{{Address: second, PartitionIds: []int32{0, 1}}}, 1)
+
+	if owner := service.GetPartitionOwner(0); owner == nil || *owner != second {
+		t.Fatalf("table after reset not applied, owner: %v", owner)
+	}
+	if count := service.PartitionCount(); count != 2 {
+		t.Fatalf("partition count: got %d, want 2", count)
+	}
+}
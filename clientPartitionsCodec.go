@@ -1,42 +1,102 @@
 package hz
 
+import "context"
+
+// The partitions owned by a single member
+type MemberPartitions struct {
+
+	Address      Address
+	PartitionIds []int32
+}
+
 func encodePartitionRequest() *ClientMessage {
 
 	message := CreateForEncode(0)
 	message.SetMessageType(CLIENT_GETPARTITIONS)
+	message.SetIsRetryable(true)
+	message.UpdateFrameLength()
+
+	return message
+}
+
+func encodeAddPartitionListenerRequest() *ClientMessage {
+
+	message := CreateForEncode(0)
+	message.SetMessageType(CLIENT_ADDPARTITIONLISTENER)
 	message.UpdateFrameLength()
 
 	return message
 }
 
-func SendPartitions(connection *ClientConnection) {
+// Decode the member to partitions list shared by the partitions response and the partitions event.
+// The partition state version is -1 when the cluster does not send one
+func DecodePartitionsResponse(message *ClientMessage) ([]MemberPartitions, int32) {
+
+	members := message.readInt()
+	result := make([]MemberPartitions, members)
+
+	for i := int32(0); i < members; i++ {
+
+		result[i].Address = decodeAddress(message)
+
+		partitions := message.readInt()
+		result[i].PartitionIds = make([]int32, partitions)
+		for j := int32(0); j < partitions; j++ {
+			result[i].PartitionIds[j] = message.readInt()
+		}
+	}
+
+	partitionStateVersion := int32(-1)
+	if message.hasUnreadData() {
+		partitionStateVersion = message.readInt()
+	}
+
+	return result, partitionStateVersion
+}
+
+func decodeAddress(message *ClientMessage) Address {
+
+	address := Address{}
+	address.Host = *message.readString()
+	address.Port = int(message.readInt())
+
+	return address
+}
+
+func SendPartitions(connection *ClientConnection) error {
+
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
+
+	return SendPartitionsWithContext(ctx, connection)
+}
+
+func SendPartitionsWithContext(ctx context.Context, connection *ClientConnection) error {
 
 	request := encodePartitionRequest()
 
 	request.SetCorrelationId(connection.NextCorrelationId())
 	request.SetFlags(BEGIN_END_FLAG)
 
-	response, _ := connection.Exchange(request)
-
-	if response.GetMessageType() != 0x006c {
-		connection.Logger.Error("Unexpected response to ping! Type: 0x%04x", response.GetMessageType())
-	} else {
-		elements := response.readInt()
-		connection.Logger.Trace("Member Partitions: %d", elements)
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		return err
+	}
 
-		for i := int32(0); i < elements; i++ {
+	if err := checkResponse(response, RESPONSE_PARTITIONS); err != nil {
+		return err
+	}
 
-			addressHost := response.readString()
-			addressPort := response.readInt()
-			partitionId := response.readInt()
+	members, _ := DecodePartitionsResponse(response)
 
-			connection.Logger.Trace("Member: %d, Partition: %s,%d,%d", i, *addressHost, addressPort, partitionId)
+	partitionCount := int32(0)
+	for i, member := range members {
+		connection.Logger.Trace("Member: %d, %s owns %d partitions", i, member.Address.String(), len(member.PartitionIds))
+		partitionCount += int32(len(member.PartitionIds))
+	}
 
-			if connection.Address.Host == *addressHost && int32(connection.Address.Port) == addressPort {
-				connection.Logger.Trace("Connected host has partition id: %d", partitionId)
-				connection.partitionCount = partitionId
-			}
-		}
+	connection.Logger.Trace("Partition count: %d", partitionCount)
+	connection.partitionCount.Store(partitionCount)
 
-	}
+	return nil
 }
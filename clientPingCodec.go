@@ -1,28 +1,42 @@
 package hz
 
+import "context"
+
 func EncodePingRequest() *ClientMessage {
 
 	message := CreateForEncode(0)
 	message.SetMessageType(CLIENT_PING)
+	message.SetIsRetryable(true)
 	message.UpdateFrameLength()
 
 	return message
 }
 
-func SendPing(connection *ClientConnection) {
+func SendPing(connection *ClientConnection) error {
+
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
+
+	return SendPingWithContext(ctx, connection)
+}
+
+func SendPingWithContext(ctx context.Context, connection *ClientConnection) error {
 
 	request := EncodePingRequest()
 
 	request.SetCorrelationId(connection.NextCorrelationId())
 	request.SetFlags(BEGIN_END_FLAG)
 
-	response, err := connection.Exchange(request)
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		return err
+	}
 
-	if nil == err {
-		if response.GetMessageType() != 0x0064 {
-			connection.Logger.Error("Unexpected response to ping! Type: 0x%04x", response.GetMessageType())
-		} else {
-			connection.Logger.Trace("Ping!")
-		}
+	if err := checkResponse(response, RESPONSE_VOID); err != nil {
+		return err
 	}
+
+	connection.Logger.Trace("Ping!")
+
+	return nil
 }
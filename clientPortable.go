@@ -0,0 +1,263 @@
+package hz
+
+import (
+	"errors"
+	"fmt"
+)
+
+/* Portable field type ids */
+const (
+
+	FIELD_TYPE_PORTABLE = 0
+	FIELD_TYPE_BYTE = 1
+	FIELD_TYPE_BOOLEAN = 2
+	FIELD_TYPE_CHAR = 3
+	FIELD_TYPE_SHORT = 4
+	FIELD_TYPE_INT = 5
+	FIELD_TYPE_LONG = 6
+	FIELD_TYPE_FLOAT = 7
+	FIELD_TYPE_DOUBLE = 8
+	FIELD_TYPE_UTF = 9
+	FIELD_TYPE_PORTABLE_ARRAY = 10
+	FIELD_TYPE_BYTE_ARRAY = 11
+	FIELD_TYPE_BOOLEAN_ARRAY = 12
+	FIELD_TYPE_CHAR_ARRAY = 13
+	FIELD_TYPE_SHORT_ARRAY = 14
+	FIELD_TYPE_INT_ARRAY = 15
+	FIELD_TYPE_LONG_ARRAY = 16
+	FIELD_TYPE_FLOAT_ARRAY = 17
+	FIELD_TYPE_DOUBLE_ARRAY = 18
+	FIELD_TYPE_UTF_ARRAY = 19
+)
+
+// Shared with java Portable classes through matching factory and class ids, fields are written and read by name
+type Portable interface {
+
+	FactoryID() int32
+	ClassID() int32
+	WritePortable(writer PortableWriter) error
+	ReadPortable(reader PortableReader) error
+}
+
+// A Portable whose class version differs from SerializationConfig.PortableVersion
+type VersionedPortable interface {
+
+	Portable
+	Version() int32
+}
+
+// Creates empty instances to read into, nil for an unknown class id
+type PortableFactory interface {
+
+	Create(classId int32) Portable
+}
+
+// Adapts a function to a PortableFactory
+type PortableFactoryFunc func(classId int32) Portable
+
+func (f PortableFactoryFunc) Create(classId int32) Portable {
+
+	return f(classId)
+}
+
+// Writes named fields, a field may only be written once. The first failure is returned once WritePortable completes
+type PortableWriter interface {
+
+	WriteUInt8(fieldName string, v byte)
+	WriteBool(fieldName string, v bool)
+	WriteUInt16(fieldName string, v uint16)
+	WriteInt16(fieldName string, v int16)
+	WriteInt32(fieldName string, v int32)
+	WriteInt64(fieldName string, v int64)
+	WriteFloat32(fieldName string, v float32)
+	WriteFloat64(fieldName string, v float64)
+	WriteUTF(fieldName string, v string)
+	WritePortable(fieldName string, v Portable)
+	// A nil portable field, the class definition must be registered unless already known
+	WriteNilPortable(fieldName string, factoryId int32, classId int32)
+	WriteByteArray(fieldName string, v []byte)
+	WriteBoolArray(fieldName string, v []bool)
+	WriteUInt16Array(fieldName string, v []uint16)
+	WriteInt16Array(fieldName string, v []int16)
+	WriteInt32Array(fieldName string, v []int32)
+	WriteInt64Array(fieldName string, v []int64)
+	WriteFloat32Array(fieldName string, v []float32)
+	WriteFloat64Array(fieldName string, v []float64)
+	WriteUTFArray(fieldName string, v []string)
+	// All elements must share a class, an empty array requires a registered class definition
+	WritePortableArray(fieldName string, v []Portable)
+}
+
+/*
+Reads named fields. Reads are sticky on error like ObjectDataInput. When the stream was written with a different class version
+than the local one, missing fields read as zero values and numeric fields widen as the java MorphingPortableReader allows
+*/
+type PortableReader interface {
+
+	// The class version the portable was written with
+	Version() int32
+	HasField(fieldName string) bool
+	FieldNames() []string
+	// The FIELD_TYPE_* of a field, -1 when not present
+	FieldType(fieldName string) int32
+
+	ReadUInt8(fieldName string) byte
+	ReadBool(fieldName string) bool
+	ReadUInt16(fieldName string) uint16
+	ReadInt16(fieldName string) int16
+	ReadInt32(fieldName string) int32
+	ReadInt64(fieldName string) int64
+	ReadFloat32(fieldName string) float32
+	ReadFloat64(fieldName string) float64
+	ReadUTF(fieldName string) string
+	ReadPortable(fieldName string) Portable
+	ReadByteArray(fieldName string) []byte
+	ReadBoolArray(fieldName string) []bool
+	ReadUInt16Array(fieldName string) []uint16
+	ReadInt16Array(fieldName string) []int16
+	ReadInt32Array(fieldName string) []int32
+	ReadInt64Array(fieldName string) []int64
+	ReadFloat32Array(fieldName string) []float32
+	ReadFloat64Array(fieldName string) []float64
+	ReadUTFArray(fieldName string) []string
+	ReadPortableArray(fieldName string) []Portable
+
+	Error() error
+}
+
+type FieldDefinition struct {
+
+	// Slot in the field offset table
+	Index int32
+	Name  string
+	Type  int32
+
+	// Class of portable and portable array fields
+	FactoryId int32
+	ClassId   int32
+
+	classDefinition *ClassDefinition
+}
+
+// The fields of one version of a portable class
+type ClassDefinition struct {
+
+	FactoryId int32
+	ClassId   int32
+	Version   int32
+
+	fields       []*FieldDefinition
+	fieldsByName map[string]*FieldDefinition
+}
+
+func (this *ClassDefinition) Field(name string) *FieldDefinition {
+
+	return this.fieldsByName[name]
+}
+
+func (this *ClassDefinition) Fields() []*FieldDefinition {
+
+	return this.fields
+}
+
+func (this *ClassDefinition) FieldCount() int {
+
+	return len(this.fields)
+}
+
+func (this *ClassDefinition) String() string {
+
+	return fmt.Sprintf("ClassDefinition: factoryId=%d, classId=%d, version=%d, fields=%d", this.FactoryId, this.ClassId, this.Version, len(this.fields))
+}
+
+func (this *ClassDefinition) sameAs(other *ClassDefinition) bool {
+
+	if this.FactoryId != other.FactoryId || this.ClassId != other.ClassId || this.Version != other.Version || len(this.fields) != len(other.fields) {
+		return false
+	}
+
+	for _, field := range this.fields {
+		otherField := other.Field(field.Name)
+		if otherField == nil || otherField.Type != field.Type || otherField.FactoryId != field.FactoryId || otherField.ClassId != field.ClassId {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Builds a ClassDefinition, fields are indexed in the order added
+type ClassDefinitionBuilder struct {
+
+	classDefinition *ClassDefinition
+	err             error
+}
+
+func NewClassDefinitionBuilder(factoryId int32, classId int32, version int32) *ClassDefinitionBuilder {
+
+	builder := new(ClassDefinitionBuilder)
+	builder.classDefinition = &ClassDefinition{FactoryId: factoryId, ClassId: classId, Version: version, fieldsByName: make(map[string]*FieldDefinition)}
+
+	return builder
+}
+
+// Add a field of any type other than FIELD_TYPE_PORTABLE and FIELD_TYPE_PORTABLE_ARRAY
+func (this *ClassDefinitionBuilder) AddField(fieldName string, fieldType int32) *ClassDefinitionBuilder {
+
+	if fieldType == FIELD_TYPE_PORTABLE || fieldType == FIELD_TYPE_PORTABLE_ARRAY {
+		this.fail(errors.New(fmt.Sprintf("Portable field %s requires a class definition", fieldName)))
+		return this
+	}
+
+	if fieldType < FIELD_TYPE_PORTABLE || fieldType > FIELD_TYPE_UTF_ARRAY {
+		this.fail(errors.New(fmt.Sprintf("Unknown field type %d for field %s", fieldType, fieldName)))
+		return this
+	}
+
+	this.add(&FieldDefinition{Name: fieldName, Type: fieldType})
+
+	return this
+}
+
+func (this *ClassDefinitionBuilder) AddPortableField(fieldName string, classDefinition *ClassDefinition) *ClassDefinitionBuilder {
+
+	this.add(&FieldDefinition{Name: fieldName, Type: FIELD_TYPE_PORTABLE, FactoryId: classDefinition.FactoryId, ClassId: classDefinition.ClassId, classDefinition: classDefinition})
+
+	return this
+}
+
+func (this *ClassDefinitionBuilder) AddPortableArrayField(fieldName string, classDefinition *ClassDefinition) *ClassDefinitionBuilder {
+
+	this.add(&FieldDefinition{Name: fieldName, Type: FIELD_TYPE_PORTABLE_ARRAY, FactoryId: classDefinition.FactoryId, ClassId: classDefinition.ClassId, classDefinition: classDefinition})
+
+	return this
+}
+
+func (this *ClassDefinitionBuilder) add(field *FieldDefinition) {
+
+	if _, ok := this.classDefinition.fieldsByName[field.Name]; ok {
+		this.fail(errors.New(fmt.Sprintf("Field %s is already defined in %v", field.Name, this.classDefinition)))
+		return
+	}
+
+	field.Index = int32(len(this.classDefinition.fields))
+	this.classDefinition.fields = append(this.classDefinition.fields, field)
+	this.classDefinition.fieldsByName[field.Name] = field
+}
+
+func (this *ClassDefinitionBuilder) fail(err error) {
+
+	if this.err == nil {
+		this.err = err
+	}
+}
+
+// The ClassDefinition, or the first error adding fields
+func (this *ClassDefinitionBuilder) Build() (*ClassDefinition, error) {
+
+	if this.err != nil {
+		return nil, this.err
+	}
+
+	return this.classDefinition, nil
+}
@@ -0,0 +1,1143 @@
+package hz
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+/*
+Portable payload: factory id, class id, then the portable itself as written by writeInternal: the class version,
+the final position, the field count and a table of field positions (one spare slot, as java reserves for raw data),
+then each field as a short name length, the name bytes, the field type and the value. Positions are absolute within
+the buffer, starting from the Data header.
+*/
+
+type classDefinitionKey struct {
+
+	factoryId int32
+	classId   int32
+	version   int32
+}
+
+// Class definitions known to the client, registered from config, built from local portables or read from streams
+type portableContext struct {
+
+	mutex sync.RWMutex
+
+	version          int32
+	factories        map[int32]PortableFactory
+	classDefinitions map[classDefinitionKey]*ClassDefinition
+}
+
+func newPortableContext(version int32, factories map[int32]PortableFactory) *portableContext {
+
+	context := new(portableContext)
+	context.version = version
+	context.factories = make(map[int32]PortableFactory)
+	context.classDefinitions = make(map[classDefinitionKey]*ClassDefinition)
+
+	for factoryId, factory := range factories {
+		context.factories[factoryId] = factory
+	}
+
+	return context
+}
+
+func (this *portableContext) versionFor(portable Portable) int32 {
+
+	if versioned, ok := portable.(VersionedPortable); ok {
+		return versioned.Version()
+	}
+
+	return this.version
+}
+
+func (this *portableContext) lookup(factoryId int32, classId int32, version int32) *ClassDefinition {
+
+	this.mutex.RLock()
+	defer this.mutex.RUnlock()
+
+	return this.classDefinitions[classDefinitionKey{factoryId, classId, version}]
+}
+
+// Register a class definition and any nested definitions it holds, returning the definition already registered if equal
+func (this *portableContext) register(classDefinition *ClassDefinition) (*ClassDefinition, error) {
+
+	key := classDefinitionKey{classDefinition.FactoryId, classDefinition.ClassId, classDefinition.Version}
+
+	this.mutex.Lock()
+	existing, ok := this.classDefinitions[key]
+	if !ok {
+		this.classDefinitions[key] = classDefinition
+	}
+	this.mutex.Unlock()
+
+	if ok {
+		if !existing.sameAs(classDefinition) {
+			return nil, errors.New(fmt.Sprintf("Incompatible class definitions with the same id: %v", classDefinition))
+		}
+		return existing, nil
+	}
+
+	for _, field := range classDefinition.fields {
+		if field.classDefinition != nil {
+			if _, err := this.register(field.classDefinition); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return classDefinition, nil
+}
+
+// The registered class definition for a portable, built from its WritePortable when not yet known
+func (this *portableContext) classDefinitionFor(portable Portable) (*ClassDefinition, error) {
+
+	version := this.versionFor(portable)
+
+	if classDefinition := this.lookup(portable.FactoryID(), portable.ClassID(), version); classDefinition != nil {
+		return classDefinition, nil
+	}
+
+	writer := &classDefinitionWriter{context: this, builder: NewClassDefinitionBuilder(portable.FactoryID(), portable.ClassID(), version)}
+	if err := portable.WritePortable(writer); err != nil {
+		return nil, err
+	}
+
+	classDefinition, err := writer.builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return this.register(classDefinition)
+}
+
+// Build the class definition from the field table of a portable in the stream, registering it unless a nested portable is missing
+func (this *portableContext) readClassDefinition(input *ObjectDataInput, factoryId int32, classId int32, version int32) (*ClassDefinition, error) {
+
+	builder := NewClassDefinitionBuilder(factoryId, classId, version)
+	register := true
+
+	input.ReadInt32() // final position
+	fieldCount := input.ReadInt32()
+	offset := input.Position()
+
+	for i := 0; i < int(fieldCount) && input.Error() == nil; i++ {
+
+		input.SetPosition(int(input.ReadInt32At(offset + i*INT_SIZE_IN_BYTES)))
+		name := string(input.next(int(input.ReadInt16())))
+		fieldType := int32(input.ReadUInt8())
+
+		var fieldFactoryId, fieldClassId int32
+
+		switch fieldType {
+		case FIELD_TYPE_PORTABLE:
+			if input.ReadBool() {
+				register = false
+			}
+			fieldFactoryId = input.ReadInt32()
+			fieldClassId = input.ReadInt32()
+
+			if register {
+				fieldVersion := input.ReadInt32()
+				if _, err := this.readClassDefinition(input, fieldFactoryId, fieldClassId, fieldVersion); err != nil {
+					return nil, err
+				}
+			}
+		case FIELD_TYPE_PORTABLE_ARRAY:
+			length := input.ReadInt32()
+			fieldFactoryId = input.ReadInt32()
+			fieldClassId = input.ReadInt32()
+
+			if length > 0 {
+				input.SetPosition(int(input.ReadInt32At(input.Position())))
+				fieldVersion := input.ReadInt32()
+				if _, err := this.readClassDefinition(input, fieldFactoryId, fieldClassId, fieldVersion); err != nil {
+					return nil, err
+				}
+			} else {
+				register = false
+			}
+		}
+
+		builder.add(&FieldDefinition{Name: name, Type: fieldType, FactoryId: fieldFactoryId, ClassId: fieldClassId})
+	}
+
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	classDefinition, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if register {
+		return this.register(classDefinition)
+	}
+
+	return classDefinition, nil
+}
+
+// Nil, or a nil pointer held in the Portable interface
+func isNilPortable(portable Portable) bool {
+
+	if portable == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(portable)
+
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+type portableSerializer struct {
+
+	context *portableContext
+}
+
+func (*portableSerializer) TypeId() int32 { return TYPE_PORTABLE }
+
+func (this *portableSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	portable := value.(Portable)
+
+	classDefinition, err := this.context.classDefinitionFor(portable)
+	if err != nil {
+		return err
+	}
+
+	output.WriteInt32(classDefinition.FactoryId)
+	output.WriteInt32(classDefinition.ClassId)
+
+	return this.writeInternal(output, portable, classDefinition)
+}
+
+func (this *portableSerializer) writeInternal(output *ObjectDataOutput, portable Portable, classDefinition *ClassDefinition) error {
+
+	output.WriteInt32(classDefinition.Version)
+
+	writer := newDefaultPortableWriter(this, output, classDefinition)
+	if err := portable.WritePortable(writer); err != nil {
+		return err
+	}
+
+	return writer.end()
+}
+
+func (this *portableSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	factoryId := input.ReadInt32()
+	classId := input.ReadInt32()
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	return this.readInternal(input, factoryId, classId)
+}
+
+func (this *portableSerializer) readInternal(input *ObjectDataInput, factoryId int32, classId int32) (Portable, error) {
+
+	version := input.ReadInt32()
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	factory, ok := this.context.factories[factoryId]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("No PortableFactory registered for factory id: %d", factoryId))
+	}
+
+	portable := factory.Create(classId)
+	if portable == nil {
+		return nil, errors.New(fmt.Sprintf("PortableFactory %d cannot create class id: %d", factoryId, classId))
+	}
+
+	classDefinition := this.context.lookup(factoryId, classId, version)
+	if classDefinition == nil {
+		begin := input.Position()
+
+		var err error
+		if classDefinition, err = this.context.readClassDefinition(input, factoryId, classId, version); err != nil {
+			return nil, err
+		}
+
+		input.SetPosition(begin)
+	}
+
+	reader := newDefaultPortableReader(this, input, classDefinition, this.context.versionFor(portable) != version)
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	if err := portable.ReadPortable(reader); err != nil {
+		return nil, err
+	}
+
+	reader.end()
+
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	return portable, nil
+}
+
+// Writes the fields of a portable against its class definition
+type defaultPortableWriter struct {
+
+	serializer      *portableSerializer
+	output          *ObjectDataOutput
+	classDefinition *ClassDefinition
+
+	begin   int
+	offset  int
+	written map[string]bool
+	err     error
+}
+
+func newDefaultPortableWriter(serializer *portableSerializer, output *ObjectDataOutput, classDefinition *ClassDefinition) *defaultPortableWriter {
+
+	writer := new(defaultPortableWriter)
+	writer.serializer = serializer
+	writer.output = output
+	writer.classDefinition = classDefinition
+	writer.written = make(map[string]bool)
+
+	writer.begin = output.Position()
+	output.WriteZeroBytes(INT_SIZE_IN_BYTES) // final position
+	output.WriteInt32(int32(classDefinition.FieldCount()))
+	writer.offset = output.Position()
+	output.WriteZeroBytes((classDefinition.FieldCount() + 1) * INT_SIZE_IN_BYTES)
+
+	return writer
+}
+
+func (this *defaultPortableWriter) fail(err error) {
+
+	if this.err == nil && err != nil {
+		this.err = err
+	}
+}
+
+// Record the field position and write its header, false when the value must not be written
+func (this *defaultPortableWriter) field(fieldName string, fieldType int32) bool {
+
+	if this.err != nil {
+		return false
+	}
+
+	field := this.classDefinition.Field(fieldName)
+	if field == nil {
+		this.fail(errors.New(fmt.Sprintf("Invalid field name: %s for %v", fieldName, this.classDefinition)))
+		return false
+	}
+
+	if field.Type != fieldType {
+		this.fail(errors.New(fmt.Sprintf("Invalid field type: %d for field %s, expected %d", fieldType, fieldName, field.Type)))
+		return false
+	}
+
+	if this.written[fieldName] {
+		this.fail(errors.New(fmt.Sprintf("Field %s has already been written", fieldName)))
+		return false
+	}
+	this.written[fieldName] = true
+
+	this.output.WriteInt32At(this.offset+int(field.Index)*INT_SIZE_IN_BYTES, int32(this.output.Position()))
+	this.output.WriteInt16(int16(len(fieldName)))
+	this.output.buffer = append(this.output.buffer, fieldName...)
+	this.output.WriteUInt8(byte(fieldType))
+
+	return true
+}
+
+func (this *defaultPortableWriter) end() error {
+
+	if this.err != nil {
+		return this.err
+	}
+
+	if len(this.written) != this.classDefinition.FieldCount() {
+		return errors.New(fmt.Sprintf("Only %d of %d fields written for %v", len(this.written), this.classDefinition.FieldCount(), this.classDefinition))
+	}
+
+	this.output.WriteInt32At(this.begin, int32(this.output.Position()))
+
+	return nil
+}
+
+func (this *defaultPortableWriter) WriteUInt8(fieldName string, v byte) {
+
+	if this.field(fieldName, FIELD_TYPE_BYTE) {
+		this.output.WriteUInt8(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteBool(fieldName string, v bool) {
+
+	if this.field(fieldName, FIELD_TYPE_BOOLEAN) {
+		this.output.WriteBool(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteUInt16(fieldName string, v uint16) {
+
+	if this.field(fieldName, FIELD_TYPE_CHAR) {
+		this.output.WriteUInt16(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteInt16(fieldName string, v int16) {
+
+	if this.field(fieldName, FIELD_TYPE_SHORT) {
+		this.output.WriteInt16(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteInt32(fieldName string, v int32) {
+
+	if this.field(fieldName, FIELD_TYPE_INT) {
+		this.output.WriteInt32(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteInt64(fieldName string, v int64) {
+
+	if this.field(fieldName, FIELD_TYPE_LONG) {
+		this.output.WriteInt64(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteFloat32(fieldName string, v float32) {
+
+	if this.field(fieldName, FIELD_TYPE_FLOAT) {
+		this.output.WriteFloat32(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteFloat64(fieldName string, v float64) {
+
+	if this.field(fieldName, FIELD_TYPE_DOUBLE) {
+		this.output.WriteFloat64(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteUTF(fieldName string, v string) {
+
+	if this.field(fieldName, FIELD_TYPE_UTF) {
+		this.output.WriteUTF(v)
+	}
+}
+
+func (this *defaultPortableWriter) WritePortable(fieldName string, v Portable) {
+
+	if !this.field(fieldName, FIELD_TYPE_PORTABLE) {
+		return
+	}
+
+	field := this.classDefinition.Field(fieldName)
+
+	this.output.WriteBool(isNilPortable(v))
+	this.output.WriteInt32(field.FactoryId)
+	this.output.WriteInt32(field.ClassId)
+
+	if !isNilPortable(v) {
+		this.writeNested(field, v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteNilPortable(fieldName string, factoryId int32, classId int32) {
+
+	if this.field(fieldName, FIELD_TYPE_PORTABLE) {
+		this.output.WriteBool(true)
+		this.output.WriteInt32(factoryId)
+		this.output.WriteInt32(classId)
+	}
+}
+
+func (this *defaultPortableWriter) WritePortableArray(fieldName string, v []Portable) {
+
+	if !this.field(fieldName, FIELD_TYPE_PORTABLE_ARRAY) {
+		return
+	}
+
+	field := this.classDefinition.Field(fieldName)
+
+	length := -1
+	if v != nil {
+		length = len(v)
+	}
+
+	this.output.WriteInt32(int32(length))
+	this.output.WriteInt32(field.FactoryId)
+	this.output.WriteInt32(field.ClassId)
+
+	if length > 0 {
+		offset := this.output.Position()
+		this.output.WriteZeroBytes(length * INT_SIZE_IN_BYTES)
+
+		for i, portable := range v {
+			this.output.WriteInt32At(offset+i*INT_SIZE_IN_BYTES, int32(this.output.Position()))
+			this.writeNested(field, portable)
+		}
+	}
+}
+
+func (this *defaultPortableWriter) writeNested(field *FieldDefinition, portable Portable) {
+
+	if isNilPortable(portable) {
+		this.fail(errors.New(fmt.Sprintf("Nil element in portable array field %s", field.Name)))
+		return
+	}
+
+	if portable.FactoryID() != field.FactoryId || portable.ClassID() != field.ClassId {
+		this.fail(errors.New(fmt.Sprintf("Portable field %s requires factoryId=%d, classId=%d, got factoryId=%d, classId=%d", field.Name, field.FactoryId, field.ClassId, portable.FactoryID(), portable.ClassID())))
+		return
+	}
+
+	classDefinition, err := this.serializer.context.classDefinitionFor(portable)
+	if err != nil {
+		this.fail(err)
+		return
+	}
+
+	this.fail(this.serializer.writeInternal(this.output, portable, classDefinition))
+}
+
+func (this *defaultPortableWriter) WriteByteArray(fieldName string, v []byte) {
+
+	if this.field(fieldName, FIELD_TYPE_BYTE_ARRAY) {
+		this.output.WriteByteArray(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteBoolArray(fieldName string, v []bool) {
+
+	if this.field(fieldName, FIELD_TYPE_BOOLEAN_ARRAY) {
+		this.output.WriteBoolArray(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteUInt16Array(fieldName string, v []uint16) {
+
+	if this.field(fieldName, FIELD_TYPE_CHAR_ARRAY) {
+		this.output.WriteUInt16Array(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteInt16Array(fieldName string, v []int16) {
+
+	if this.field(fieldName, FIELD_TYPE_SHORT_ARRAY) {
+		this.output.WriteInt16Array(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteInt32Array(fieldName string, v []int32) {
+
+	if this.field(fieldName, FIELD_TYPE_INT_ARRAY) {
+		this.output.WriteInt32Array(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteInt64Array(fieldName string, v []int64) {
+
+	if this.field(fieldName, FIELD_TYPE_LONG_ARRAY) {
+		this.output.WriteInt64Array(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteFloat32Array(fieldName string, v []float32) {
+
+	if this.field(fieldName, FIELD_TYPE_FLOAT_ARRAY) {
+		this.output.WriteFloat32Array(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteFloat64Array(fieldName string, v []float64) {
+
+	if this.field(fieldName, FIELD_TYPE_DOUBLE_ARRAY) {
+		this.output.WriteFloat64Array(v)
+	}
+}
+
+func (this *defaultPortableWriter) WriteUTFArray(fieldName string, v []string) {
+
+	if this.field(fieldName, FIELD_TYPE_UTF_ARRAY) {
+		this.output.WriteUTFArray(v)
+	}
+}
+
+// Builds a class definition from the fields a portable writes
+type classDefinitionWriter struct {
+
+	context *portableContext
+	builder *ClassDefinitionBuilder
+}
+
+func (this *classDefinitionWriter) WriteUInt8(fieldName string, v byte) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_BYTE)
+}
+
+func (this *classDefinitionWriter) WriteBool(fieldName string, v bool) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_BOOLEAN)
+}
+
+func (this *classDefinitionWriter) WriteUInt16(fieldName string, v uint16) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_CHAR)
+}
+
+func (this *classDefinitionWriter) WriteInt16(fieldName string, v int16) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_SHORT)
+}
+
+func (this *classDefinitionWriter) WriteInt32(fieldName string, v int32) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_INT)
+}
+
+func (this *classDefinitionWriter) WriteInt64(fieldName string, v int64) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_LONG)
+}
+
+func (this *classDefinitionWriter) WriteFloat32(fieldName string, v float32) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_FLOAT)
+}
+
+func (this *classDefinitionWriter) WriteFloat64(fieldName string, v float64) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_DOUBLE)
+}
+
+func (this *classDefinitionWriter) WriteUTF(fieldName string, v string) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_UTF)
+}
+
+func (this *classDefinitionWriter) WritePortable(fieldName string, v Portable) {
+
+	if isNilPortable(v) {
+		this.builder.fail(errors.New(fmt.Sprintf("Cannot write nil portable field %s without a registered class definition, use WriteNilPortable", fieldName)))
+		return
+	}
+
+	classDefinition, err := this.context.classDefinitionFor(v)
+	if err != nil {
+		this.builder.fail(err)
+		return
+	}
+
+	this.builder.AddPortableField(fieldName, classDefinition)
+}
+
+func (this *classDefinitionWriter) WriteNilPortable(fieldName string, factoryId int32, classId int32) {
+
+	classDefinition := this.context.lookup(factoryId, classId, this.context.version)
+	if classDefinition == nil {
+		this.builder.fail(errors.New(fmt.Sprintf("Cannot write nil portable field %s without a registered class definition for factoryId=%d, classId=%d", fieldName, factoryId, classId)))
+		return
+	}
+
+	this.builder.AddPortableField(fieldName, classDefinition)
+}
+
+func (this *classDefinitionWriter) WritePortableArray(fieldName string, v []Portable) {
+
+	if len(v) == 0 || isNilPortable(v[0]) {
+		this.builder.fail(errors.New(fmt.Sprintf("Cannot write empty portable array field %s without a registered class definition", fieldName)))
+		return
+	}
+
+	classDefinition, err := this.context.classDefinitionFor(v[0])
+	if err != nil {
+		this.builder.fail(err)
+		return
+	}
+
+	this.builder.AddPortableArrayField(fieldName, classDefinition)
+}
+
+func (this *classDefinitionWriter) WriteByteArray(fieldName string, v []byte) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_BYTE_ARRAY)
+}
+
+func (this *classDefinitionWriter) WriteBoolArray(fieldName string, v []bool) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_BOOLEAN_ARRAY)
+}
+
+func (this *classDefinitionWriter) WriteUInt16Array(fieldName string, v []uint16) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_CHAR_ARRAY)
+}
+
+func (this *classDefinitionWriter) WriteInt16Array(fieldName string, v []int16) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_SHORT_ARRAY)
+}
+
+func (this *classDefinitionWriter) WriteInt32Array(fieldName string, v []int32) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_INT_ARRAY)
+}
+
+func (this *classDefinitionWriter) WriteInt64Array(fieldName string, v []int64) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_LONG_ARRAY)
+}
+
+func (this *classDefinitionWriter) WriteFloat32Array(fieldName string, v []float32) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_FLOAT_ARRAY)
+}
+
+func (this *classDefinitionWriter) WriteFloat64Array(fieldName string, v []float64) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_DOUBLE_ARRAY)
+}
+
+func (this *classDefinitionWriter) WriteUTFArray(fieldName string, v []string) {
+
+	this.builder.AddField(fieldName, FIELD_TYPE_UTF_ARRAY)
+}
+
+// Reads fields through the field position table, morphing when the local class version differs from the stream
+type defaultPortableReader struct {
+
+	serializer      *portableSerializer
+	input           *ObjectDataInput
+	classDefinition *ClassDefinition
+	morphing        bool
+
+	finalPosition int
+	offset        int
+}
+
+func newDefaultPortableReader(serializer *portableSerializer, input *ObjectDataInput, classDefinition *ClassDefinition, morphing bool) *defaultPortableReader {
+
+	reader := new(defaultPortableReader)
+	reader.serializer = serializer
+	reader.input = input
+	reader.classDefinition = classDefinition
+	reader.morphing = morphing
+
+	reader.finalPosition = int(input.ReadInt32())
+	fieldCount := input.ReadInt32()
+	if input.Error() == nil && int(fieldCount) != classDefinition.FieldCount() {
+		input.SetError(errors.New(fmt.Sprintf("Field count %d in stream does not match %v", fieldCount, classDefinition)))
+	}
+	reader.offset = input.Position()
+
+	return reader
+}
+
+func (this *defaultPortableReader) end() {
+
+	this.input.SetPosition(this.finalPosition)
+}
+
+func (this *defaultPortableReader) Version() int32 {
+
+	return this.classDefinition.Version
+}
+
+func (this *defaultPortableReader) HasField(fieldName string) bool {
+
+	return this.classDefinition.Field(fieldName) != nil
+}
+
+func (this *defaultPortableReader) FieldNames() []string {
+
+	names := make([]string, 0, this.classDefinition.FieldCount())
+	for _, field := range this.classDefinition.fields {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
+
+func (this *defaultPortableReader) FieldType(fieldName string) int32 {
+
+	if field := this.classDefinition.Field(fieldName); field != nil {
+		return field.Type
+	}
+
+	return -1
+}
+
+func (this *defaultPortableReader) Error() error {
+
+	return this.input.Error()
+}
+
+/*
+Position of a field value and its type. The first type is the one expected, any others are only accepted when morphing.
+Returns -1 when the field cannot be read, a missing field is only an error when not morphing
+*/
+func (this *defaultPortableReader) position(fieldName string, fieldTypes ...int32) (int, int32) {
+
+	if this.input.Error() != nil {
+		return -1, -1
+	}
+
+	field := this.classDefinition.Field(fieldName)
+	if field == nil {
+		if !this.morphing {
+			this.input.SetError(errors.New(fmt.Sprintf("Unknown field name: %s for %v", fieldName, this.classDefinition)))
+		}
+		return -1, -1
+	}
+
+	accepted := field.Type == fieldTypes[0]
+	for _, fieldType := range fieldTypes[1:] {
+		accepted = accepted || (this.morphing && field.Type == fieldType)
+	}
+
+	if !accepted {
+		this.input.SetError(errors.New(fmt.Sprintf("Incompatible type %d for field %s, expected %d", field.Type, fieldName, fieldTypes[0])))
+		return -1, -1
+	}
+
+	position := int(this.input.ReadInt32At(this.offset + int(field.Index)*INT_SIZE_IN_BYTES))
+
+	current := this.input.Position()
+	this.input.SetPosition(position)
+	nameLength := int(this.input.ReadInt16())
+	this.input.SetPosition(current)
+
+	return position + SHORT_SIZE_IN_BYTES + nameLength + BYTE_SIZE_IN_BYTES, field.Type
+}
+
+// Run read at position, restoring the current position afterwards
+func (this *defaultPortableReader) at(position int, read func()) {
+
+	current := this.input.Position()
+
+	this.input.SetPosition(position)
+	read()
+	this.input.SetPosition(current)
+}
+
+// Read an integral field of any narrower type, widening as java does
+func (this *defaultPortableReader) readIntegral(position int, fieldType int32) int64 {
+
+	var v int64
+
+	this.at(position, func() {
+		switch fieldType {
+		case FIELD_TYPE_BYTE:
+			v = int64(int8(this.input.ReadUInt8()))
+		case FIELD_TYPE_CHAR:
+			v = int64(this.input.ReadUInt16())
+		case FIELD_TYPE_SHORT:
+			v = int64(this.input.ReadInt16())
+		case FIELD_TYPE_INT:
+			v = int64(this.input.ReadInt32())
+		case FIELD_TYPE_LONG:
+			v = this.input.ReadInt64()
+		}
+	})
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadUInt8(fieldName string) byte {
+
+	var v byte
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_BYTE); position >= 0 {
+		this.at(position, func() { v = this.input.ReadUInt8() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadBool(fieldName string) bool {
+
+	var v bool
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_BOOLEAN); position >= 0 {
+		this.at(position, func() { v = this.input.ReadBool() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadUInt16(fieldName string) uint16 {
+
+	var v uint16
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_CHAR); position >= 0 {
+		this.at(position, func() { v = this.input.ReadUInt16() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadInt16(fieldName string) int16 {
+
+	if position, fieldType := this.position(fieldName, FIELD_TYPE_SHORT, FIELD_TYPE_BYTE); position >= 0 {
+		return int16(this.readIntegral(position, fieldType))
+	}
+
+	return 0
+}
+
+func (this *defaultPortableReader) ReadInt32(fieldName string) int32 {
+
+	if position, fieldType := this.position(fieldName, FIELD_TYPE_INT, FIELD_TYPE_BYTE, FIELD_TYPE_CHAR, FIELD_TYPE_SHORT); position >= 0 {
+		return int32(this.readIntegral(position, fieldType))
+	}
+
+	return 0
+}
+
+func (this *defaultPortableReader) ReadInt64(fieldName string) int64 {
+
+	if position, fieldType := this.position(fieldName, FIELD_TYPE_LONG, FIELD_TYPE_BYTE, FIELD_TYPE_CHAR, FIELD_TYPE_SHORT, FIELD_TYPE_INT); position >= 0 {
+		return this.readIntegral(position, fieldType)
+	}
+
+	return 0
+}
+
+func (this *defaultPortableReader) ReadFloat32(fieldName string) float32 {
+
+	position, fieldType := this.position(fieldName, FIELD_TYPE_FLOAT, FIELD_TYPE_BYTE, FIELD_TYPE_CHAR, FIELD_TYPE_SHORT, FIELD_TYPE_INT)
+	if position < 0 {
+		return 0
+	}
+
+	if fieldType != FIELD_TYPE_FLOAT {
+		return float32(this.readIntegral(position, fieldType))
+	}
+
+	var v float32
+	this.at(position, func() { v = this.input.ReadFloat32() })
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadFloat64(fieldName string) float64 {
+
+	position, fieldType := this.position(fieldName, FIELD_TYPE_DOUBLE, FIELD_TYPE_BYTE, FIELD_TYPE_CHAR, FIELD_TYPE_SHORT, FIELD_TYPE_INT, FIELD_TYPE_LONG, FIELD_TYPE_FLOAT)
+	if position < 0 {
+		return 0
+	}
+
+	var v float64
+
+	switch fieldType {
+	case FIELD_TYPE_DOUBLE:
+		this.at(position, func() { v = this.input.ReadFloat64() })
+	case FIELD_TYPE_FLOAT:
+		this.at(position, func() { v = float64(this.input.ReadFloat32()) })
+	default:
+		v = float64(this.readIntegral(position, fieldType))
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadUTF(fieldName string) string {
+
+	var v string
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_UTF); position >= 0 {
+		this.at(position, func() { v = this.input.ReadUTF() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadPortable(fieldName string) Portable {
+
+	var v Portable
+
+	position, _ := this.position(fieldName, FIELD_TYPE_PORTABLE)
+	if position < 0 {
+		return nil
+	}
+
+	field := this.classDefinition.Field(fieldName)
+
+	this.at(position, func() {
+		isNil := this.input.ReadBool()
+		factoryId := this.input.ReadInt32()
+		classId := this.input.ReadInt32()
+
+		if this.input.Error() != nil || !this.checkClass(field, factoryId, classId) || isNil {
+			return
+		}
+
+		portable, err := this.serializer.readInternal(this.input, factoryId, classId)
+		if err != nil {
+			this.input.SetError(err)
+			return
+		}
+		v = portable
+	})
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadPortableArray(fieldName string) []Portable {
+
+	var v []Portable
+
+	position, _ := this.position(fieldName, FIELD_TYPE_PORTABLE_ARRAY)
+	if position < 0 {
+		return nil
+	}
+
+	field := this.classDefinition.Field(fieldName)
+
+	this.at(position, func() {
+		length := this.input.ReadInt32()
+		factoryId := this.input.ReadInt32()
+		classId := this.input.ReadInt32()
+
+		if this.input.Error() != nil || length < 0 || !this.checkClass(field, factoryId, classId) {
+			return
+		}
+
+		if int(length)*INT_SIZE_IN_BYTES > this.input.Available() {
+			this.input.SetError(fmt.Errorf("%w: portable array of %d elements for field %s", ErrInvalidData, length, fieldName))
+			return
+		}
+
+		v = make([]Portable, length)
+		offset := this.input.Position()
+
+		for i := range v {
+			this.input.SetPosition(int(this.input.ReadInt32At(offset + i*INT_SIZE_IN_BYTES)))
+
+			portable, err := this.serializer.readInternal(this.input, factoryId, classId)
+			if err != nil {
+				this.input.SetError(err)
+				return
+			}
+			v[i] = portable
+		}
+	})
+
+	return v
+}
+
+func (this *defaultPortableReader) checkClass(field *FieldDefinition, factoryId int32, classId int32) bool {
+
+	if factoryId != field.FactoryId || classId != field.ClassId {
+		this.input.SetError(errors.New(fmt.Sprintf("Portable field %s has factoryId=%d, classId=%d, expected factoryId=%d, classId=%d", field.Name, factoryId, classId, field.FactoryId, field.ClassId)))
+		return false
+	}
+
+	return true
+}
+
+func (this *defaultPortableReader) ReadByteArray(fieldName string) []byte {
+
+	var v []byte
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_BYTE_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadByteArray() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadBoolArray(fieldName string) []bool {
+
+	var v []bool
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_BOOLEAN_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadBoolArray() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadUInt16Array(fieldName string) []uint16 {
+
+	var v []uint16
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_CHAR_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadUInt16Array() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadInt16Array(fieldName string) []int16 {
+
+	var v []int16
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_SHORT_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadInt16Array() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadInt32Array(fieldName string) []int32 {
+
+	var v []int32
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_INT_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadInt32Array() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadInt64Array(fieldName string) []int64 {
+
+	var v []int64
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_LONG_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadInt64Array() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadFloat32Array(fieldName string) []float32 {
+
+	var v []float32
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_FLOAT_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadFloat32Array() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadFloat64Array(fieldName string) []float64 {
+
+	var v []float64
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_DOUBLE_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadFloat64Array() })
+	}
+
+	return v
+}
+
+func (this *defaultPortableReader) ReadUTFArray(fieldName string) []string {
+
+	var v []string
+
+	if position, _ := this.position(fieldName, FIELD_TYPE_UTF_ARRAY); position >= 0 {
+		this.at(position, func() { v = this.input.ReadUTFArray() })
+	}
+
+	return v
+}
@@ -0,0 +1,293 @@
+package hz
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type point struct {
+
+	X int32
+}
+
+func (*point) FactoryID() int32 { return 1 }
+func (*point) ClassID() int32   { return 2 }
+
+func (this *point) WritePortable(writer PortableWriter) error {
+
+	writer.WriteInt32("x", this.X)
+
+	return nil
+}
+
+func (this *point) ReadPortable(reader PortableReader) error {
+
+	this.X = reader.ReadInt32("x")
+
+	return reader.Error()
+}
+
+// A nested portable, a portable array and a field that is always nil
+type line struct {
+
+	Start  *point
+	Points []Portable
+}
+
+func (*line) FactoryID() int32 { return 1 }
+func (*line) ClassID() int32   { return 4 }
+
+func (this *line) WritePortable(writer PortableWriter) error {
+
+	writer.WritePortable("start", this.Start)
+	writer.WritePortableArray("points", this.Points)
+	writer.WriteNilPortable("end", 1, 2)
+
+	return nil
+}
+
+func (this *line) ReadPortable(reader PortableReader) error {
+
+	if start, ok := reader.ReadPortable("start").(*point); ok {
+		this.Start = start
+	}
+	this.Points = reader.ReadPortableArray("points")
+	if reader.ReadPortable("end") != nil {
+		return errors.New("end must be nil")
+	}
+
+	return reader.Error()
+}
+
+// The first version of a class
+type labelV1 struct {
+
+	Text  string
+	Width int16
+}
+
+func (*labelV1) FactoryID() int32 { return 1 }
+func (*labelV1) ClassID() int32   { return 5 }
+
+func (this *labelV1) WritePortable(writer PortableWriter) error {
+
+	writer.WriteUTF("text", this.Text)
+	writer.WriteInt16("width", this.Width)
+
+	return nil
+}
+
+func (this *labelV1) ReadPortable(reader PortableReader) error {
+
+	this.Text = reader.ReadUTF("text")
+	this.Width = reader.ReadInt16("width")
+
+	return reader.Error()
+}
+
+// The second version widened width and added color
+type labelV2 struct {
+
+	Text  string
+	Width int32
+	Color string
+
+	// The version read from the stream
+	ReadVersion int32
+}
+
+func (*labelV2) FactoryID() int32 { return 1 }
+func (*labelV2) ClassID() int32   { return 5 }
+func (*labelV2) Version() int32   { return 2 }
+
+func (this *labelV2) WritePortable(writer PortableWriter) error {
+
+	writer.WriteUTF("text", this.Text)
+	writer.WriteInt32("width", this.Width)
+	writer.WriteUTF("color", this.Color)
+
+	return nil
+}
+
+func (this *labelV2) ReadPortable(reader PortableReader) error {
+
+	this.Text = reader.ReadUTF("text")
+	this.Width = reader.ReadInt32("width")
+	this.Color = reader.ReadUTF("color")
+	this.ReadVersion = reader.Version()
+
+	return reader.Error()
+}
+
+// A fresh service, class definitions not registered are read from the stream
+func newPortableService(t *testing.T, labelVersion int32, classDefinitions ...*ClassDefinition) *SerializationService {
+
+	config := NewSerializationConfig()
+	for _, classDefinition := range classDefinitions {
+		config.AddClassDefinition(classDefinition)
+	}
+	config.AddPortableFactory(1, PortableFactoryFunc(func(classId int32) Portable {
+		switch classId {
+		case 2:
+			return &point{}
+		case 4:
+			return &line{}
+		case 5:
+			if labelVersion == 2 {
+				return &labelV2{}
+			}
+			return &labelV1{}
+		}
+		return nil
+	}))
+
+	service, err := NewSerializationService(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return service
+}
+
+// As written by the java client, field offsets are positions in the whole Data
+var (
+	pointData = Data{
+		0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, // partition hash, TYPE_PORTABLE
+		0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 0, // factory id, class id, version
+		0, 0, 0, 44, // end of the portable
+		0, 0, 0, 1, // field count
+		0, 0, 0, 36, 0, 0, 0, 0, // field offsets
+		0, 1, 'x', FIELD_TYPE_INT, 0, 0, 0, 5,
+	}
+
+	lineData = Data{
+		0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff,
+		0, 0, 0, 1, 0, 0, 0, 4, 0, 0, 0, 0,
+		0, 0, 0, 157,
+		0, 0, 0, 3,
+		0, 0, 0, 44, 0, 0, 0, 89, 0, 0, 0, 142, 0, 0, 0, 0,
+		// start: not nil, factory id, class id, then the nested point
+		0, 5, 's', 't', 'a', 'r', 't', FIELD_TYPE_PORTABLE, 0, 0, 0, 0, 1, 0, 0, 0, 2,
+		0, 0, 0, 0, 0, 0, 0, 89, 0, 0, 0, 1, 0, 0, 0, 81, 0, 0, 0, 0, 0, 1, 'x', FIELD_TYPE_INT, 0, 0, 0, 1,
+		// points: length, factory id, class id, element offsets, then the elements
+		0, 6, 'p', 'o', 'i', 'n', 't', 's', FIELD_TYPE_PORTABLE_ARRAY, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 114,
+		0, 0, 0, 0, 0, 0, 0, 142, 0, 0, 0, 1, 0, 0, 0, 134, 0, 0, 0, 0, 0, 1, 'x', FIELD_TYPE_INT, 0, 0, 0, 2,
+		// end: nil
+		0, 3, 'e', 'n', 'd', FIELD_TYPE_PORTABLE, 1, 0, 0, 0, 1, 0, 0, 0, 2,
+	}
+)
+
+func TestPortableBytes(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		value Portable
+		data  Data
+	}{
+		{"portable", &point{X: 5}, pointData},
+		{"nested portables", &line{Start: &point{X: 1}, Points: []Portable{&point{X: 2}}}, lineData},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			data, err := newPortableService(t, 1).ToData(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, test.data) {
+				t.Fatalf("got % x\nwant % x", []byte(data), []byte(test.data))
+			}
+
+			object, err := newPortableService(t, 1).ToObject(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(object, test.value) {
+				t.Fatalf("got %#v, want %#v", object, test.value)
+			}
+		})
+	}
+}
+
+func TestPortableRoundTrip(t *testing.T) {
+
+	// As in java a nil portable or empty portable array can only be written with a registered class definition
+	pointDefinition, err := NewClassDefinitionBuilder(1, 2, 0).AddField("x", FIELD_TYPE_INT).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lineDefinition, err := NewClassDefinitionBuilder(1, 4, 0).
+		AddPortableField("start", pointDefinition).
+		AddPortableArrayField("points", pointDefinition).
+		AddPortableField("end", pointDefinition).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value Portable
+	}{
+		{"nil nested portable", &line{Points: []Portable{&point{X: 1}}}},
+		{"portable array", &line{Start: &point{X: 1}, Points: []Portable{&point{X: 2}, &point{X: 3}, &point{X: 4}}}},
+		{"empty portable array", &line{Start: &point{X: 1}, Points: []Portable{}}},
+		{"nil portable array", &line{Start: &point{X: 1}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			data, err := newPortableService(t, 1, pointDefinition, lineDefinition).ToData(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			object, err := newPortableService(t, 1).ToObject(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(object, test.value) {
+				t.Fatalf("got %#v, want %#v", object, test.value)
+			}
+		})
+	}
+}
+
+func TestPortableVersions(t *testing.T) {
+
+	data, err := newPortableService(t, 1).ToData(&labelV1{Text: "exit", Width: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The newer class reads the old fields it still has, widening width, and finds no color
+	object, err := newPortableService(t, 2).ToObject(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&labelV2{Text: "exit", Width: 12, ReadVersion: 0}); !reflect.DeepEqual(object, want) {
+		t.Fatalf("got %#v, want %#v", object, want)
+	}
+
+	data, err = newPortableService(t, 2).ToData(&labelV2{Text: "exit", Width: 12, Color: "red"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Version 2 written with a 32 bit width cannot be narrowed by the older class
+	if _, err := newPortableService(t, 1).ToObject(data); err == nil {
+		t.Fatal("int field read as a short")
+	}
+
+	object, err = newPortableService(t, 2).ToObject(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&labelV2{Text: "exit", Width: 12, Color: "red", ReadVersion: 2}); !reflect.DeepEqual(object, want) {
+		t.Fatalf("got %#v, want %#v", object, want)
+	}
+}
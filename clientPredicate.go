@@ -0,0 +1,65 @@
+package hz
+
+import (
+	"errors"
+	"fmt"
+)
+
+/* Factory id of the java predicates, com.hazelcast.query.impl.predicates.PredicateDataSerializerHook. The other class ids are in the predicates package */
+const (
+
+	PREDICATE_FACTORY_ID = -32
+
+	PAGING_PREDICATE = 15
+)
+
+/*
+A query evaluated on the cluster against map entries, built with the predicates package.
+Any IdentifiedDataSerializable with a factory registered on the cluster can be used as a custom predicate.
+*/
+type Predicate interface {
+
+	IdentifiedDataSerializable
+}
+
+// Constructors of the predicate classes by class id, the predicates package registers its classes from init
+var predicateClasses = map[int32]func() IdentifiedDataSerializable{
+
+	PAGING_PREDICATE: func() IdentifiedDataSerializable { return &PagingPredicate{} },
+}
+
+// Create predicates of the class id read from the stream with create. Only call from an init function
+func RegisterPredicateClass(classId int32, create func() IdentifiedDataSerializable) {
+
+	predicateClasses[classId] = create
+}
+
+// Creates the predicates read from the stream, registered with every SerializationService
+var predicateFactory = DataSerializableFactoryFunc(func(classId int32) IdentifiedDataSerializable {
+
+	if create, ok := predicateClasses[classId]; ok {
+		return create()
+	}
+
+	return nil
+})
+
+// Read a predicate written as an object, nil when a nil predicate was written
+func ReadPredicate(input *ObjectDataInput) (Predicate, error) {
+
+	object := input.ReadObject()
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	if object == nil {
+		return nil, nil
+	}
+
+	predicate, ok := object.(Predicate)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Expected a predicate, read: %T", object))
+	}
+
+	return predicate, nil
+}
@@ -0,0 +1,465 @@
+package hz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+
+	QUEUE_SERVICE_NAME = "hz:impl:queueService"
+
+	ITEM_ADDED = 1
+	ITEM_REMOVED = 2
+)
+
+// Returned by Add when a bounded queue has no space, the java client throws IllegalStateException("Queue is full!") on the client
+// side as the protocol has no error code for it
+var ErrQueueFull = errors.New("Queue is full")
+
+// An item added to or removed from a queue. Item is only set when the listener includes values
+type ItemEvent[T any] struct {
+
+	Name       string
+	Item       T
+	HasItem    bool
+	MemberUuid string
+	EventType  int32
+}
+
+// A distributed queue holding items of type T, serialized with the client SerializationService
+type Queue[T any] struct {
+
+	name        string
+	client      *HazelcastClient
+	partitionId int32
+}
+
+// Get a queue proxy, creating the queue on the cluster the first time the name is used by this client.
+// A function rather than a method of HazelcastClient as Go methods cannot have type parameters
+func GetQueue[T any](ctx context.Context, client *HazelcastClient, name string) (*Queue[T], error) {
+
+	if err := client.createProxy(ctx, name, QUEUE_SERVICE_NAME); err != nil {
+		return nil, err
+	}
+
+	partitionId, err := client.namePartitionId(name)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := new(Queue[T])
+	queue.name = name
+	queue.client = client
+	queue.partitionId = partitionId
+
+	return queue, nil
+}
+
+func (this *Queue[T]) Name() string {
+
+	return this.name
+}
+
+// Add an item, waiting for space if the queue is bounded. Only ctx limits the wait, the invocation timeout does not apply
+func (this *Queue[T]) Put(ctx context.Context, item T) error {
+
+	data, err := this.toData(item)
+	if err != nil {
+		return err
+	}
+
+	_, err = this.invokeBlocking(ctx, EncodeQueuePutRequest(this.name, data), RESPONSE_VOID, -1)
+
+	return err
+}
+
+// Take the head of the queue, waiting up to timeout for an item. False when no item arrives in time.
+// The invocation timeout is extended by timeout so a long poll is not cut short
+func (this *Queue[T]) Poll(ctx context.Context, timeout time.Duration) (T, bool, error) {
+
+	response, err := this.invokeBlocking(ctx, EncodeQueuePollRequest(this.name, uint64(timeout/time.Millisecond)), RESPONSE_DATA, timeout)
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+
+	return this.readNullableItem(response)
+}
+
+// Add an item without waiting, ErrQueueFull when a bounded queue has no space
+func (this *Queue[T]) Add(ctx context.Context, item T) error {
+
+	added, err := this.Offer(ctx, item, 0)
+	if err != nil {
+		return err
+	}
+
+	if !added {
+		return fmt.Errorf("%w: %s", ErrQueueFull, this.name)
+	}
+
+	return nil
+}
+
+// Add an item, waiting up to timeout for space if the queue is bounded. False when no space became available in time.
+// The invocation timeout is extended by timeout so a long offer is not cut short
+func (this *Queue[T]) Offer(ctx context.Context, item T, timeout time.Duration) (bool, error) {
+
+	data, err := this.toData(item)
+	if err != nil {
+		return false, err
+	}
+
+	response, err := this.invokeBlocking(ctx, EncodeQueueOfferRequest(this.name, data, int64(timeout/time.Millisecond)), RESPONSE_BOOLEAN, timeout)
+	if err != nil {
+		return false, err
+	}
+
+	return DecodeQueueBooleanResponse(response), nil
+}
+
+// Take the head of the queue, waiting until an item is available or ctx is done. Only ctx limits the wait, the invocation timeout does not apply
+func (this *Queue[T]) Take(ctx context.Context) (T, error) {
+
+	var item T
+
+	response, err := this.invokeBlocking(ctx, EncodeQueueTakeRequest(this.name), RESPONSE_DATA, -1)
+	if err != nil {
+		return item, err
+	}
+
+	item, _, err = this.readNullableItem(response)
+
+	return item, err
+}
+
+// The head of the queue without removing it, false when empty
+func (this *Queue[T]) Peek(ctx context.Context) (T, bool, error) {
+
+	response, err := this.invoke(ctx, EncodeQueuePeekRequest(this.name), RESPONSE_DATA)
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+
+	return this.readNullableItem(response)
+}
+
+func (this *Queue[T]) Size(ctx context.Context) (int32, error) {
+
+	return this.invokeInt(ctx, EncodeQueueSizeRequest(this.name))
+}
+
+func (this *Queue[T]) IsEmpty(ctx context.Context) (bool, error) {
+
+	return this.invokeBool(ctx, EncodeQueueIsEmptyRequest(this.name))
+}
+
+// The number of items that can be added without blocking, math.MaxInt32 for an unbounded queue
+func (this *Queue[T]) RemainingCapacity(ctx context.Context) (int32, error) {
+
+	return this.invokeInt(ctx, EncodeQueueRemainingCapacityRequest(this.name))
+}
+
+// Items are compared by their serialized form
+func (this *Queue[T]) Contains(ctx context.Context, item T) (bool, error) {
+
+	data, err := this.toData(item)
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, EncodeQueueContainsRequest(this.name, data))
+}
+
+func (this *Queue[T]) ContainsAll(ctx context.Context, items []T) (bool, error) {
+
+	dataList, err := this.toDataList(items)
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, EncodeQueueContainsAllRequest(this.name, dataList))
+}
+
+// Remove one occurrence of an item, false when not present
+func (this *Queue[T]) Remove(ctx context.Context, item T) (bool, error) {
+
+	data, err := this.toData(item)
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, EncodeQueueRemoveRequest(this.name, data))
+}
+
+// Add all items in order, true when the queue changed
+func (this *Queue[T]) AddAll(ctx context.Context, items []T) (bool, error) {
+
+	dataList, err := this.toDataList(items)
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, EncodeQueueAddAllRequest(this.name, dataList))
+}
+
+// Remove every occurrence of the items, true when the queue changed
+func (this *Queue[T]) RemoveAll(ctx context.Context, items []T) (bool, error) {
+
+	dataList, err := this.toDataList(items)
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, EncodeQueueCompareAndRemoveAllRequest(this.name, dataList))
+}
+
+// Remove every item not in items, true when the queue changed
+func (this *Queue[T]) RetainAll(ctx context.Context, items []T) (bool, error) {
+
+	dataList, err := this.toDataList(items)
+	if err != nil {
+		return false, err
+	}
+
+	return this.invokeBool(ctx, EncodeQueueCompareAndRetainAllRequest(this.name, dataList))
+}
+
+// Remove and return all items
+func (this *Queue[T]) DrainTo(ctx context.Context) ([]T, error) {
+
+	return this.invokeItems(ctx, EncodeQueueDrainToRequest(this.name))
+}
+
+// Remove and return at most maxSize items
+func (this *Queue[T]) DrainToMaxSize(ctx context.Context, maxSize int32) ([]T, error) {
+
+	return this.invokeItems(ctx, EncodeQueueDrainToMaxSizeRequest(this.name, maxSize))
+}
+
+// A snapshot of the items in queue order, the queue is not changed
+func (this *Queue[T]) Items(ctx context.Context) ([]T, error) {
+
+	return this.invokeItems(ctx, EncodeQueueIteratorRequest(this.name))
+}
+
+// Remove all items
+func (this *Queue[T]) Clear(ctx context.Context) error {
+
+	_, err := this.invoke(ctx, EncodeQueueClearRequest(this.name), RESPONSE_VOID)
+
+	return err
+}
+
+// Call the listener for each item added or removed, in the order the events arrive. The listener runs on a single goroutine
+// and stays registered across reconnects until RemoveItemListener is called with the returned id or the client shuts down
+func (this *Queue[T]) AddItemListener(ctx context.Context, includeValue bool, listener func(ItemEvent[T])) (string, error) {
+
+	id, cb, err := this.client.StartQueueListener(ctx, this.name, includeValue)
+	if err != nil {
+		return "", err
+	}
+
+	done := this.client.ListenerService().Done(id)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-this.client.shutdown:
+				return
+			case message := <-cb.NotifyChannel:
+				if event, ok := this.itemEvent(message); ok {
+					listener(event)
+				}
+			}
+		}
+	}()
+
+	return id, nil
+}
+
+// Deliver events to the channel. Events are dropped with a warning when the channel is full
+func (this *Queue[T]) AddItemChannel(ctx context.Context, includeValue bool, channel chan<- ItemEvent[T]) (string, error) {
+
+	return this.AddItemListener(ctx, includeValue, func(event ItemEvent[T]) {
+		select {
+		case channel <- event:
+		default:
+			this.client.Logger.Warn("Item channel full for queue %s, dropped event: %d", this.name, event.EventType)
+		}
+	})
+}
+
+// Deregister the listener on the cluster and stop delivering its events
+func (this *Queue[T]) RemoveItemListener(ctx context.Context, id string) error {
+
+	return this.client.StopQueueListener(ctx, this.name, id)
+}
+
+// Destroy the queue and its items on the cluster
+func (this *Queue[T]) Destroy(ctx context.Context) error {
+
+	return this.client.destroyProxy(ctx, this.name, QUEUE_SERVICE_NAME)
+}
+
+func (this *Queue[T]) invoke(ctx context.Context, request *ClientMessage, responseType uint16) (*ClientMessage, error) {
+
+	return this.invokeBlocking(ctx, request, responseType, 0)
+}
+
+// Invoke an operation that may wait on the queue for up to blockFor, negative to wait until ctx is done
+func (this *Queue[T]) invokeBlocking(ctx context.Context, request *ClientMessage, responseType uint16, blockFor time.Duration) (*ClientMessage, error) {
+
+	request.SetPartitionId(this.partitionId)
+
+	response, err := this.client.InvocationService().InvokeBlockingWithContext(ctx, request, blockFor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkResponse(response, responseType); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (this *Queue[T]) invokeBool(ctx context.Context, request *ClientMessage) (bool, error) {
+
+	response, err := this.invoke(ctx, request, RESPONSE_BOOLEAN)
+	if err != nil {
+		return false, err
+	}
+
+	return DecodeQueueBooleanResponse(response), nil
+}
+
+func (this *Queue[T]) invokeInt(ctx context.Context, request *ClientMessage) (int32, error) {
+
+	response, err := this.invoke(ctx, request, RESPONSE_INTEGER)
+	if err != nil {
+		return 0, err
+	}
+
+	return DecodeQueueIntegerResponse(response), nil
+}
+
+func (this *Queue[T]) invokeItems(ctx context.Context, request *ClientMessage) ([]T, error) {
+
+	response, err := this.invoke(ctx, request, RESPONSE_LIST_DATA)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.toItems(DecodeQueueListDataResponse(response))
+}
+
+func (this *Queue[T]) itemEvent(message *ClientMessage) (ItemEvent[T], bool) {
+
+	if message.GetMessageType() != EVENT_ITEM {
+		this.client.Logger.Warn("Unexpected item event type: 0x%04x for queue %s", message.GetMessageType(), this.name)
+		return ItemEvent[T]{}, false
+	}
+
+	data, uuid, eventType := DecodeItemEvent(message)
+
+	event := ItemEvent[T]{Name: this.name, MemberUuid: uuid, EventType: eventType}
+
+	if data != nil {
+		item, err := this.toItem(data)
+		if err != nil {
+			this.client.Logger.Error("Dropped item event for queue %s: %v", this.name, err)
+			return event, false
+		}
+		event.Item = item
+		event.HasItem = true
+	}
+
+	return event, true
+}
+
+func (this *Queue[T]) toData(item T) (Data, error) {
+
+	data, err := this.client.SerializationService().ToData(item)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, errors.New(fmt.Sprintf("Queue %s does not allow nil items", this.name))
+	}
+
+	return data, nil
+}
+
+func (this *Queue[T]) toItem(data Data) (T, error) {
+
+	var item T
+
+	object, err := this.client.SerializationService().ToObject(data)
+	if err != nil {
+		return item, err
+	}
+
+	if object == nil {
+		return item, nil
+	}
+
+	item, ok := castObject[T](object)
+	if !ok {
+		return item, errors.New(fmt.Sprintf("Queue %s item of type %T is not a %T", this.name, object, item))
+	}
+
+	return item, nil
+}
+
+func (this *Queue[T]) toDataList(items []T) ([]Data, error) {
+
+	dataList := make([]Data, len(items))
+	for i, item := range items {
+		data, err := this.toData(item)
+		if err != nil {
+			return nil, err
+		}
+		dataList[i] = data
+	}
+
+	return dataList, nil
+}
+
+func (this *Queue[T]) toItems(dataList []Data) ([]T, error) {
+
+	items := make([]T, len(dataList))
+	for i, data := range dataList {
+		item, err := this.toItem(data)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+
+	return items, nil
+}
+
+// A nullable Data response, false when null
+func (this *Queue[T]) readNullableItem(response *ClientMessage) (T, bool, error) {
+
+	var item T
+
+	if response.readBool() {
+		return item, false, nil
+	}
+
+	item, err := this.toItem(response.readData())
+	if err != nil {
+		return item, false, err
+	}
+
+	return item, true, nil
+}
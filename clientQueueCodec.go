@@ -1,15 +1,18 @@
 package hz
 
 import (
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
 )
 
-func EncodeQueuePutRequest(name string, byteArray [] byte) *ClientMessage {
+func EncodeQueuePutRequest(name string, data Data) *ClientMessage {
 
-	message := CreateForEncode(CalculateSizeStr(&name) + (len(byteArray) + INT_SIZE_IN_BYTES))
+	message := CreateForEncode(CalculateSizeStr(&name) + (len(data) + INT_SIZE_IN_BYTES))
 	message.SetMessageType(CLIENT_QUEUE_PUT)
 	message.AppendStr(&name)
-	message.AppendByteArray(byteArray)
+	message.AppendByteArray(data)
 
 	message.UpdateFrameLength()
 
@@ -39,7 +42,15 @@ func EncodeQueueClearRequest(name string) *ClientMessage {
 	return message
 }
 
-func SendQueueClearRequest(connection *ClientConnection, name string) {
+func SendQueueClearRequest(connection *ClientConnection, name string) error {
+
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
+
+	return SendQueueClearRequestWithContext(ctx, connection, name)
+}
+
+func SendQueueClearRequestWithContext(ctx context.Context, connection *ClientConnection, name string) error {
 
 	request := EncodeQueueClearRequest(name)
 
@@ -50,18 +61,24 @@ func SendQueueClearRequest(connection *ClientConnection, name string) {
 	request.SetPartitionId(CalcHash(connection, []byte(append(nlbuffer, name...))))
 	request.SetFlags(BEGIN_END_FLAG)
 
-	response, _ := connection.Exchange(request)
-
-	if response.GetMessageType() != 0x0064 {
-		connection.Logger.Error("Unexpected response to queue CLEAR request ! Type: 0x%04x", response.GetMessageType())
-		if response.GetMessageType() == 0x006d {
-			connection.Logger.Error("    Error Code: %d", response.readInt())
-			connection.Logger.Error("    Class Name: %s", *response.readString())
-		}
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		return err
 	}
+
+	return checkResponse(response, RESPONSE_VOID)
 }
 
-func SendQueuePollRequest(connection *ClientConnection, name string, timeout uint64) [] byte {
+// Poll for an item, nil Data when none arrives before the timeout
+func SendQueuePollRequest(connection *ClientConnection, name string, timeout uint64) (Data, error) {
+
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
+
+	return SendQueuePollRequestWithContext(ctx, connection, name, timeout)
+}
+
+func SendQueuePollRequestWithContext(ctx context.Context, connection *ClientConnection, name string, timeout uint64) (Data, error) {
 
 	request := EncodeQueuePollRequest(name, timeout)
 
@@ -72,58 +89,43 @@ func SendQueuePollRequest(connection *ClientConnection, name string, timeout uin
 	request.SetPartitionId(CalcHash(connection, []byte(append(nlbuffer, name...))))
 	request.SetFlags(BEGIN_END_FLAG)
 
-	response, _ := connection.Exchange(request)
-
-	if response.GetMessageType() != 0x0069 {
-		connection.Logger.Error("Unexpected response to queue POLL request ! Type: 0x%04x", response.GetMessageType())
-		if response.GetMessageType() == 0x006d {
-			connection.Logger.Error("    Error Code: %d", response.readInt())
-			connection.Logger.Error("    Class Name: %s", *response.readString())
-		}
-
-	} else {
-
-		if !response.readBool() {
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
 
-			response.readInt()   // length
-			response.readBEInt() // partition hash?
+	if err := checkResponse(response, RESPONSE_DATA); err != nil {
+		return nil, err
+	}
 
-			serializerTypeId := response.readBEInt()
-			if uint32(serializerTypeId) != connection.QueueSerializerId {
-				connection.Logger.Error("Queue POLL response, invalid serializer type: %d", serializerTypeId)
+	if !response.readBool() {
 
-			} else {
-				byteArray := response.readBEByteArray()
-				connection.Logger.Trace("Queue POLL successful to %s, %d bytes received", name, len(byteArray))
-				return byteArray
-			}
-		}
+		data := Data(response.readByteArray())
+		connection.Logger.Trace("Queue POLL successful to %s, type id: %d, %d bytes received", name, data.TypeId(), len(data))
+		return data, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
-func SendQueuePutRequest(connection *ClientConnection, name string, byteArray [] byte) {
+// Put an item serialized by the SerializationService
+func SendQueuePutRequest(connection *ClientConnection, name string, data Data) error {
 
-	connection.Logger.Trace("Send message to queue: %s content: %s", name, string(byteArray))
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
 
-	// Partition Hash?  I'll set to zero!!
-	pbuffer := make([]byte, INT_SIZE_IN_BYTES)
-	binary.BigEndian.PutUint32(pbuffer, 0)
+	return SendQueuePutRequestWithContext(ctx, connection, name, data)
+}
 
-	// Serializer Id - override the default value on ClientConnection if custom serializer is being used
-	tbuffer := make([]byte, INT_SIZE_IN_BYTES)
-	binary.BigEndian.PutUint32(tbuffer, connection.QueueSerializerId)
+func SendQueuePutRequestWithContext(ctx context.Context, connection *ClientConnection, name string, data Data) error {
 
-	// Length of ByteArray for serializer
-	lbuffer := make([]byte, INT_SIZE_IN_BYTES)
-	binary.BigEndian.PutUint32(lbuffer, uint32(len(byteArray)))
+	if data == nil {
+		return errors.New(fmt.Sprintf("Queue PUT to %s, null items are not allowed", name))
+	}
 
-	b1 := append(lbuffer, byteArray...)
-	b2 := append(tbuffer, b1...)
-	b3 := append(pbuffer, b2...)
+	connection.Logger.Trace("Send message to queue: %s type id: %d", name, data.TypeId())
 
-	request := EncodeQueuePutRequest(name, b3)
+	request := EncodeQueuePutRequest(name, data)
 
 	request.SetCorrelationId(connection.NextCorrelationId())
 
@@ -133,54 +135,64 @@ func SendQueuePutRequest(connection *ClientConnection, name string, byteArray []
 	request.SetPartitionId(CalcHash(connection, []byte(append(nlbuffer, name...))))
 	request.SetFlags(BEGIN_END_FLAG)
 
-	response, _ := connection.Exchange(request)
-
-	if response.GetMessageType() != 0x0064 {
-		connection.Logger.Error("Unexpected response to queue PUT request ! Type: 0x%04x", response.GetMessageType())
-		if response.GetMessageType() == 0x006d {
-			connection.Logger.Error("    Error Code: %d", response.readInt())
-			connection.Logger.Error("    Class Name: %s", *response.readString())
-		}
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		return err
+	}
 
-	} else {
-		connection.Logger.Trace("Queue PUT successful to %s, %d bytes", name, len(byteArray))
+	if err := checkResponse(response, RESPONSE_VOID); err != nil {
+		return err
 	}
+
+	connection.Logger.Trace("Queue PUT successful to %s, %d bytes", name, len(data))
+
+	return nil
+}
+
+// Add an item listener on the connection, events arrive on the returned callback NotifyChannel and decode with DecodeItemEvent
+func StartQueueListener(connection *ClientConnection, name string, includeValue bool) (*ResponseCallback, error) {
+
+	ctx, cancel := defaultExchangeContext()
+	defer cancel()
+
+	return StartQueueListenerWithContext(ctx, connection, name, includeValue)
 }
 
-func StartQueueListener(connection *ClientConnection, name string) ResponseCallback {
+func StartQueueListenerWithContext(ctx context.Context, connection *ClientConnection, name string, includeValue bool) (*ResponseCallback, error) {
 
-	request := EncodeAddListenerRequest(name)
+	request := EncodeAddListenerRequest(name, includeValue)
 
 	request.SetCorrelationId(connection.NextCorrelationId())
 	request.SetPartitionId(-1)
 	request.SetFlags(BEGIN_END_FLAG)
 
-	response, _ := connection.Exchange(request)
+	cb := connection.RegisterListener(request.GetCorrelationId())
 
-	if response.GetMessageType() != 0x0068 {
-		connection.Logger.Error("Unexpected response to queue add listener request ! Type: 0x%04x", response.GetMessageType())
-		if response.GetMessageType() == 0x006d {
-			connection.Logger.Error("    Error Code: %d", response.readInt())
-			connection.Logger.Error("    Class Name: %s", *response.readString())
-		}
+	response, err := connection.ExchangeWithContext(ctx, request)
+	if err != nil {
+		connection.UnregisterListener(request.GetCorrelationId())
+		return nil, err
+	}
 
-	} else {
-		connection.Logger.Trace("Queue ADD LISTENER successful to %s, registrationId: %s", name, *response.readString())
+	if err := checkResponse(response, RESPONSE_STRING); err != nil {
+		connection.UnregisterListener(request.GetCorrelationId())
+		return nil, err
 	}
 
-	cb := connection.Register(request.GetCorrelationId())
-	cb.autoRemove = false
+	connection.Logger.Trace("Queue ADD LISTENER successful to %s, registrationId: %s", name, *response.readString())
 
-	return *cb
+	return cb, nil
 }
 
-func EncodeAddListenerRequest(name string) *ClientMessage {
+// Response: RESPONSE_STRING, the registration id. Events carry the item only when includeValue is set
+func EncodeAddListenerRequest(name string, includeValue bool) *ClientMessage {
 
 	message := CreateForEncode(CalculateSizeStr(&name) + BYTE_SIZE_IN_BYTES + BYTE_SIZE_IN_BYTES)
 
 	message.SetMessageType(CLIENT_QUEUE_ADD_LISTENER)
 	message.AppendStr(&name)
-	message.AppendBool(false)
+	message.AppendBool(includeValue)
+	// localOnly
 	message.AppendBool(false)
 
 	message.UpdateFrameLength()
@@ -188,19 +200,189 @@ func EncodeAddListenerRequest(name string) *ClientMessage {
 	return message
 }
 
-func ProcessQueueEvent(clientMessage *ClientMessage, connection *ClientConnection, name string) []byte {
+// EVENT_ITEM: the item (nil unless the listener includes values), the uuid of the member the event came from and ITEM_ADDED or ITEM_REMOVED
+func DecodeItemEvent(event *ClientMessage) (Data, string, int32) {
 
-	if !clientMessage.readBool() {
-		// Ignore content as we'll poll() for it
-	}
-	uuid := clientMessage.readString()
-	eventType := clientMessage.readInt()
+	item := event.readNullableData()
+	uuid := *event.readString()
+	eventType := event.readInt()
 
-	connection.Logger.Trace("Processing queue event: %s, %d", *uuid, eventType)
+	return item, uuid, eventType
+}
 
-	if eventType == 1 {
-		// An item has been added, so go get it
-		return SendQueuePollRequest(connection, name, 0)
-	}
-	return nil
+func EncodeQueueRemoveListenerRequest(name string, registrationId string) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeStr(&registrationId))
+	message.SetMessageType(CLIENT_QUEUE_REMOVE_LISTENER)
+	message.AppendStr(&name)
+	message.AppendStr(&registrationId)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_BOOLEAN, false when no space became available within the timeout
+func EncodeQueueOfferRequest(name string, data Data, timeoutMillis int64) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(data) + INT64_SIZE_IN_BYTES)
+	message.SetMessageType(CLIENT_QUEUE_OFFER)
+	message.AppendStr(&name)
+	message.AppendByteArray(data)
+	message.AppendInt64(uint64(timeoutMillis))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_DATA, blocks until an item is available
+func EncodeQueueTakeRequest(name string) *ClientMessage {
+
+	return encodeQueueNameRequest(CLIENT_QUEUE_TAKE, name)
+}
+
+// Response: RESPONSE_DATA, null when empty
+func EncodeQueuePeekRequest(name string) *ClientMessage {
+
+	return encodeQueueNameRequest(CLIENT_QUEUE_PEEK, name)
+}
+
+// Response: RESPONSE_INTEGER
+func EncodeQueueSizeRequest(name string) *ClientMessage {
+
+	return encodeQueueNameRequest(CLIENT_QUEUE_SIZE, name)
+}
+
+// Response: RESPONSE_BOOLEAN
+func EncodeQueueIsEmptyRequest(name string) *ClientMessage {
+
+	return encodeQueueNameRequest(CLIENT_QUEUE_IS_EMPTY, name)
+}
+
+// Response: RESPONSE_INTEGER
+func EncodeQueueRemainingCapacityRequest(name string) *ClientMessage {
+
+	return encodeQueueNameRequest(CLIENT_QUEUE_REMAINING_CAPACITY, name)
+}
+
+// Response: RESPONSE_LIST_DATA, the items in queue order
+func EncodeQueueIteratorRequest(name string) *ClientMessage {
+
+	return encodeQueueNameRequest(CLIENT_QUEUE_ITERATOR, name)
+}
+
+// Response: RESPONSE_LIST_DATA, the items removed
+func EncodeQueueDrainToRequest(name string) *ClientMessage {
+
+	return encodeQueueNameRequest(CLIENT_QUEUE_DRAIN_TO, name)
+}
+
+// Response: RESPONSE_LIST_DATA, at most maxSize items removed
+func EncodeQueueDrainToMaxSizeRequest(name string, maxSize int32) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + INT_SIZE_IN_BYTES)
+	message.SetMessageType(CLIENT_QUEUE_DRAIN_TO_MAX_SIZE)
+	message.AppendStr(&name)
+	message.AppendInt(int(maxSize))
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+// Response: RESPONSE_BOOLEAN
+func EncodeQueueContainsRequest(name string, data Data) *ClientMessage {
+
+	return encodeQueueDataRequest(CLIENT_QUEUE_CONTAINS, name, data)
+}
+
+// Response: RESPONSE_BOOLEAN, true when an item was removed
+func EncodeQueueRemoveRequest(name string, data Data) *ClientMessage {
+
+	return encodeQueueDataRequest(CLIENT_QUEUE_REMOVE, name, data)
+}
+
+// Response: RESPONSE_BOOLEAN
+func EncodeQueueContainsAllRequest(name string, dataList []Data) *ClientMessage {
+
+	return encodeQueueDataListRequest(CLIENT_QUEUE_CONTAINS_ALL, name, dataList)
+}
+
+// Response: RESPONSE_BOOLEAN, true when the queue changed
+func EncodeQueueAddAllRequest(name string, dataList []Data) *ClientMessage {
+
+	return encodeQueueDataListRequest(CLIENT_QUEUE_ADD_ALL, name, dataList)
+}
+
+// Response: RESPONSE_BOOLEAN, true when the queue changed
+func EncodeQueueCompareAndRemoveAllRequest(name string, dataList []Data) *ClientMessage {
+
+	return encodeQueueDataListRequest(CLIENT_QUEUE_COMPARE_AND_REMOVE_ALL, name, dataList)
+}
+
+// Response: RESPONSE_BOOLEAN, true when the queue changed
+func EncodeQueueCompareAndRetainAllRequest(name string, dataList []Data) *ClientMessage {
+
+	return encodeQueueDataListRequest(CLIENT_QUEUE_COMPARE_AND_RETAIN_ALL, name, dataList)
+}
+
+func encodeQueueNameRequest(messageType uint16, name string) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name))
+	message.SetMessageType(messageType)
+	message.AppendStr(&name)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+func encodeQueueDataRequest(messageType uint16, name string, data Data) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeData(data))
+	message.SetMessageType(messageType)
+	message.AppendStr(&name)
+	message.AppendByteArray(data)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+func encodeQueueDataListRequest(messageType uint16, name string, dataList []Data) *ClientMessage {
+
+	message := CreateForEncode(CalculateSizeStr(&name) + CalculateSizeDataList(dataList))
+	message.SetMessageType(messageType)
+	message.AppendStr(&name)
+	message.AppendDataList(dataList)
+
+	message.UpdateFrameLength()
+
+	return message
+}
+
+/*
+	Response decoders
+ */
+
+func DecodeQueueBooleanResponse(response *ClientMessage) bool {
+
+	return response.readBool()
+}
+
+func DecodeQueueIntegerResponse(response *ClientMessage) int32 {
+
+	return response.readInt()
+}
+
+// Nil when the response holds null
+func DecodeQueueDataResponse(response *ClientMessage) Data {
+
+	return response.readNullableData()
+}
+
+func DecodeQueueListDataResponse(response *ClientMessage) []Data {
+
+	return response.readDataList()
 }
@@ -0,0 +1,146 @@
+package hz
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The message as received by the other side, read from a copy of the encoded frame
+func receivedMessage(t *testing.T, message *ClientMessage) *ClientMessage {
+
+	t.Helper()
+
+	length := int(message.GetFrameLength())
+	if length != len(message.Buffer) {
+		t.Fatalf("frame length %d, buffer %d bytes: payload size miscalculated", length, len(message.Buffer))
+	}
+
+	return CreateForDecode(append([]byte(nil), message.Buffer[:length]...))
+}
+
+func TestQueueRequestCodecs(t *testing.T) {
+
+	name := "jobs"
+	item := Data{0, 0, 0, 0, 0xff, 0xff, 0xff, 0xf7, 0, 0, 0, 1, 'a'}
+	other := Data{0, 0, 0, 0, 0xff, 0xff, 0xff, 0xf7, 0, 0, 0, 1, 'b'}
+	items := []Data{item, other}
+
+	readName := func(message *ClientMessage) interface{} { return *message.readString() }
+	readData := func(message *ClientMessage) interface{} { return []interface{}{*message.readString(), message.readData()} }
+	readDataList := func(message *ClientMessage) interface{} { return []interface{}{*message.readString(), message.readDataList()} }
+
+	tests := []struct {
+		name        string
+		request     *ClientMessage
+		messageType uint16
+		read        func(*ClientMessage) interface{}
+		want        interface{}
+	}{
+		{"offer", EncodeQueueOfferRequest(name, item, 1500), 0x0301,
+			func(message *ClientMessage) interface{} { return []interface{}{*message.readString(), message.readData(), message.readInt64()} },
+			[]interface{}{name, item, int64(1500)}},
+		{"put", EncodeQueuePutRequest(name, item), 0x0302, readData, []interface{}{name, item}},
+		{"size", EncodeQueueSizeRequest(name), 0x0303, readName, name},
+		{"remove", EncodeQueueRemoveRequest(name, item), 0x0304, readData, []interface{}{name, item}},
+		{"poll", EncodeQueuePollRequest(name, 250), 0x0305,
+			func(message *ClientMessage) interface{} { return []interface{}{*message.readString(), message.readInt64()} },
+			[]interface{}{name, int64(250)}},
+		{"take", EncodeQueueTakeRequest(name), 0x0306, readName, name},
+		{"peek", EncodeQueuePeekRequest(name), 0x0307, readName, name},
+		{"iterator", EncodeQueueIteratorRequest(name), 0x0308, readName, name},
+		{"drain to", EncodeQueueDrainToRequest(name), 0x0309, readName, name},
+		{"drain to max size", EncodeQueueDrainToMaxSizeRequest(name, 7), 0x030a,
+			func(message *ClientMessage) interface{} { return []interface{}{*message.readString(), message.readInt()} },
+			[]interface{}{name, int32(7)}},
+		{"contains", EncodeQueueContainsRequest(name, item), 0x030b, readData, []interface{}{name, item}},
+		{"contains all", EncodeQueueContainsAllRequest(name, items), 0x030c, readDataList, []interface{}{name, items}},
+		{"compare and remove all", EncodeQueueCompareAndRemoveAllRequest(name, items), 0x030d, readDataList, []interface{}{name, items}},
+		{"compare and retain all", EncodeQueueCompareAndRetainAllRequest(name, items), 0x030e, readDataList, []interface{}{name, items}},
+		{"clear", EncodeQueueClearRequest(name), 0x030f, readName, name},
+		{"add all", EncodeQueueAddAllRequest(name, items), 0x0310, readDataList, []interface{}{name, items}},
+		{"empty add all", EncodeQueueAddAllRequest(name, []Data{}), 0x0310, readDataList, []interface{}{name, []Data{}}},
+		{"add listener", EncodeAddListenerRequest(name, true), 0x0311,
+			func(message *ClientMessage) interface{} { return []interface{}{*message.readString(), message.readBool(), message.readBool()} },
+			[]interface{}{name, true, false}},
+		{"remove listener", EncodeQueueRemoveListenerRequest(name, "registration"), 0x0312,
+			func(message *ClientMessage) interface{} { return []interface{}{*message.readString(), *message.readString()} },
+			[]interface{}{name, "registration"}},
+		{"remaining capacity", EncodeQueueRemainingCapacityRequest(name), 0x0313, readName, name},
+		{"is empty", EncodeQueueIsEmptyRequest(name), 0x0314, readName, name},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			message := receivedMessage(t, test.request)
+
+			if message.GetMessageType() != test.messageType {
+				t.Fatalf("message type: got 0x%04x, want 0x%04x", message.GetMessageType(), test.messageType)
+			}
+
+			if got := test.read(message); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+
+			if message.hasUnreadData() {
+				t.Fatal("unread payload left")
+			}
+		})
+	}
+}
+
+func TestQueueResponseCodecs(t *testing.T) {
+
+	item := Data{0, 0, 0, 0, 0xff, 0xff, 0xff, 0xf7, 0, 0, 0, 1, 'a'}
+	items := []Data{item, item}
+
+	tests := []struct {
+		name     string
+		response *ClientMessage
+		decode   func(*ClientMessage) interface{}
+		want     interface{}
+	}{
+		{"boolean", boolResponse(true), func(message *ClientMessage) interface{} { return DecodeQueueBooleanResponse(message) }, true},
+		{"integer", intResponse(42), func(message *ClientMessage) interface{} { return DecodeQueueIntegerResponse(message) }, int32(42)},
+		{"data", dataResponse(item), func(message *ClientMessage) interface{} { return DecodeQueueDataResponse(message) }, item},
+		{"null data", dataResponse(nil), func(message *ClientMessage) interface{} { return DecodeQueueDataResponse(message) }, Data(nil)},
+		{"list", listResponse(items), func(message *ClientMessage) interface{} { return DecodeQueueListDataResponse(message) }, items},
+		{"empty list", listResponse(nil), func(message *ClientMessage) interface{} { return DecodeQueueListDataResponse(message) }, []Data{}},
+		{"item event", itemEventMessage(item, "member", ITEM_REMOVED), func(message *ClientMessage) interface{} {
+			data, uuid, eventType := DecodeItemEvent(message)
+			return []interface{}{data, uuid, eventType}
+		}, []interface{}{item, "member", int32(ITEM_REMOVED)}},
+		{"item event without item", itemEventMessage(nil, "member", ITEM_ADDED), func(message *ClientMessage) interface{} {
+			data, uuid, eventType := DecodeItemEvent(message)
+			return []interface{}{data, uuid, eventType}
+		}, []interface{}{Data(nil), "member", int32(ITEM_ADDED)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			message := receivedMessage(t, test.response)
+
+			if got := test.decode(message); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+
+			if message.hasUnreadData() {
+				t.Fatal("unread payload left")
+			}
+		})
+	}
+}
+
+// EVENT_ITEM as sent to a queue item listener
+func itemEventMessage(data Data, uuid string, eventType int32) *ClientMessage {
+
+	event := newStubResponse(EVENT_ITEM, CalculateSizeNullableData(data) + CalculateSizeStr(&uuid) + INT_SIZE_IN_BYTES)
+	event.SetFlags(BEGIN_END_FLAG | LISTENER_FLAG)
+
+	event.AppendNullableData(data)
+	event.AppendStr(&uuid)
+	event.AppendInt(int(eventType))
+
+	return finishStubResponse(event)
+}
@@ -0,0 +1,377 @@
+package hz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// An in memory bounded queue behind the stub member
+type stubQueue struct {
+
+	mutex    sync.Mutex
+	items    []Data
+	capacity int
+
+	// Of the last queue request
+	partitionId int32
+	timeout     int64
+
+	// Sent when a listener is added
+	events []*ClientMessage
+	removedListeners []string
+
+	// Delays TAKE responses
+	takeDelay time.Duration
+}
+
+func (this *stubQueue) index(data Data) int {
+
+	for i, item := range this.items {
+		if bytes.Equal(item, data) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// The partition id and timeout of the last queue request
+func (this *stubQueue) last() (int32, int64) {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	return this.partitionId, this.timeout
+}
+
+func (this *stubQueue) handle(request *ClientMessage) []*ClientMessage {
+
+	messageType := request.GetMessageType()
+	if messageType < CLIENT_QUEUE_OFFER || messageType > CLIENT_QUEUE_IS_EMPTY {
+		return nil
+	}
+
+	if messageType == CLIENT_QUEUE_TAKE && this.takeDelay > 0 {
+		time.Sleep(this.takeDelay)
+	}
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.partitionId = request.GetPartitionId()
+	request.readString()
+
+	switch messageType {
+	case CLIENT_QUEUE_OFFER:
+		item := request.readData()
+		this.timeout = request.readInt64()
+		if len(this.items) >= this.capacity {
+			return []*ClientMessage{boolResponse(false)}
+		}
+		this.items = append(this.items, item)
+		return []*ClientMessage{boolResponse(true)}
+	case CLIENT_QUEUE_PUT:
+		this.items = append(this.items, request.readData())
+		return []*ClientMessage{voidResponse()}
+	case CLIENT_QUEUE_POLL, CLIENT_QUEUE_TAKE:
+		if messageType == CLIENT_QUEUE_POLL {
+			this.timeout = request.readInt64()
+		}
+		if len(this.items) == 0 {
+			return []*ClientMessage{dataResponse(nil)}
+		}
+		item := this.items[0]
+		this.items = this.items[1:]
+		return []*ClientMessage{dataResponse(item)}
+	case CLIENT_QUEUE_PEEK:
+		if len(this.items) == 0 {
+			return []*ClientMessage{dataResponse(nil)}
+		}
+		return []*ClientMessage{dataResponse(this.items[0])}
+	case CLIENT_QUEUE_SIZE:
+		return []*ClientMessage{intResponse(int32(len(this.items)))}
+	case CLIENT_QUEUE_IS_EMPTY:
+		return []*ClientMessage{boolResponse(len(this.items) == 0)}
+	case CLIENT_QUEUE_REMAINING_CAPACITY:
+		return []*ClientMessage{intResponse(int32(this.capacity - len(this.items)))}
+	case CLIENT_QUEUE_CONTAINS:
+		return []*ClientMessage{boolResponse(this.index(request.readData()) >= 0)}
+	case CLIENT_QUEUE_CONTAINS_ALL:
+		all := true
+		for _, item := range request.readDataList() {
+			all = all && this.index(item) >= 0
+		}
+		return []*ClientMessage{boolResponse(all)}
+	case CLIENT_QUEUE_REMOVE:
+		i := this.index(request.readData())
+		if i >= 0 {
+			this.items = append(this.items[:i], this.items[i+1:]...)
+		}
+		return []*ClientMessage{boolResponse(i >= 0)}
+	case CLIENT_QUEUE_ADD_ALL:
+		items := request.readDataList()
+		this.items = append(this.items, items...)
+		return []*ClientMessage{boolResponse(len(items) > 0)}
+	case CLIENT_QUEUE_COMPARE_AND_REMOVE_ALL, CLIENT_QUEUE_COMPARE_AND_RETAIN_ALL:
+		given := &stubQueue{items: request.readDataList()}
+		var kept []Data
+		for _, item := range this.items {
+			if (given.index(item) >= 0) == (messageType == CLIENT_QUEUE_COMPARE_AND_RETAIN_ALL) {
+				kept = append(kept, item)
+			}
+		}
+		changed := len(kept) != len(this.items)
+		this.items = kept
+		return []*ClientMessage{boolResponse(changed)}
+	case CLIENT_QUEUE_ITERATOR:
+		return []*ClientMessage{listResponse(this.items)}
+	case CLIENT_QUEUE_DRAIN_TO, CLIENT_QUEUE_DRAIN_TO_MAX_SIZE:
+		count := len(this.items)
+		if messageType == CLIENT_QUEUE_DRAIN_TO_MAX_SIZE {
+			count = min(count, int(request.readInt()))
+		}
+		drained := this.items[:count]
+		this.items = this.items[count:]
+		return []*ClientMessage{listResponse(drained)}
+	case CLIENT_QUEUE_CLEAR:
+		this.items = nil
+		return []*ClientMessage{voidResponse()}
+	case CLIENT_QUEUE_ADD_LISTENER:
+		return append([]*ClientMessage{stringResponse("item-registration")}, this.events...)
+	case CLIENT_QUEUE_REMOVE_LISTENER:
+		this.removedListeners = append(this.removedListeners, *request.readString())
+		return []*ClientMessage{boolResponse(true)}
+	}
+
+	return nil
+}
+
+func TestQueueOperations(t *testing.T) {
+
+	stub := &stubQueue{capacity: 5}
+	client, _ := newStubClient(t, stub.handle)
+	ctx := context.Background()
+
+	queue, err := GetQueue[string](ctx, client, "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, got interface{}, err error, want interface{}) {
+
+		t.Helper()
+
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+	}
+
+	empty, err := queue.IsEmpty(ctx)
+	check("IsEmpty", empty, err, true)
+
+	nameData, _ := client.SerializationService().ToData("jobs")
+	partitionId, _ := stub.last()
+	check("partition", partitionId, nil, nameData.PartitionId(client.PartitionService().PartitionCount()))
+
+	check("Put", nil, queue.Put(ctx, "a"), nil)
+
+	offered, err := queue.Offer(ctx, "b", 1500 * time.Millisecond)
+	check("Offer", offered, err, true)
+	_, timeout := stub.last()
+	check("Offer timeout", timeout, nil, int64(1500))
+
+	check("Add", nil, queue.Add(ctx, "c"), nil)
+
+	changed, err := queue.AddAll(ctx, []string{"d", "e"})
+	check("AddAll", changed, err, true)
+
+	size, err := queue.Size(ctx)
+	check("Size", size, err, int32(5))
+
+	capacity, err := queue.RemainingCapacity(ctx)
+	check("RemainingCapacity", capacity, err, int32(0))
+
+	offered, err = queue.Offer(ctx, "f", 0)
+	check("Offer full", offered, err, false)
+
+	contains, err := queue.Contains(ctx, "c")
+	check("Contains", contains, err, true)
+
+	contains, err = queue.ContainsAll(ctx, []string{"a", "z"})
+	check("ContainsAll", contains, err, false)
+
+	head, ok, err := queue.Peek(ctx)
+	check("Peek", []interface{}{head, ok}, err, []interface{}{"a", true})
+
+	taken, err := queue.Take(ctx)
+	check("Take", taken, err, "a")
+
+	polled, ok, err := queue.Poll(ctx, 250 * time.Millisecond)
+	check("Poll", []interface{}{polled, ok}, err, []interface{}{"b", true})
+	_, timeout = stub.last()
+	check("Poll timeout", timeout, nil, int64(250))
+
+	removed, err := queue.Remove(ctx, "c")
+	check("Remove", removed, err, true)
+
+	removed, err = queue.Remove(ctx, "c")
+	check("Remove missing", removed, err, false)
+
+	items, err := queue.Items(ctx)
+	check("Items", items, err, []string{"d", "e"})
+
+	queue.AddAll(ctx, []string{"f", "g"})
+
+	changed, err = queue.RemoveAll(ctx, []string{"d"})
+	check("RemoveAll", changed, err, true)
+
+	changed, err = queue.RetainAll(ctx, []string{"e", "f", "g"})
+	check("RetainAll unchanged", changed, err, false)
+
+	drained, err := queue.DrainToMaxSize(ctx, 2)
+	check("DrainToMaxSize", drained, err, []string{"e", "f"})
+
+	drained, err = queue.DrainTo(ctx)
+	check("DrainTo", drained, err, []string{"g"})
+
+	polled, ok, err = queue.Poll(ctx, 0)
+	check("Poll empty", []interface{}{polled, ok}, err, []interface{}{"", false})
+
+	head, ok, err = queue.Peek(ctx)
+	check("Peek empty", []interface{}{head, ok}, err, []interface{}{"", false})
+
+	queue.Put(ctx, "h")
+	check("Clear", nil, queue.Clear(ctx), nil)
+
+	size, err = queue.Size(ctx)
+	check("Size after Clear", size, err, int32(0))
+
+	check("Destroy", nil, queue.Destroy(ctx), nil)
+}
+
+func TestQueueAddWhenFull(t *testing.T) {
+
+	stub := &stubQueue{capacity: 1}
+	client, _ := newStubClient(t, stub.handle)
+	ctx := context.Background()
+
+	queue, err := GetQueue[int](ctx, client, "bounded")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := queue.Add(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := queue.Add(ctx, 2); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("got %v, want ErrQueueFull", err)
+	}
+
+	if _, timeout := stub.last(); timeout != 0 {
+		t.Fatalf("Add must not wait, offered with timeout %d", timeout)
+	}
+
+	// Go ints travel as a java Long
+	item, err := queue.Take(ctx)
+	if err != nil || item != 1 {
+		t.Fatalf("got %v, %v", item, err)
+	}
+}
+
+func TestQueueTakeOutlivesInvocationTimeout(t *testing.T) {
+
+	stub := &stubQueue{capacity: 1, takeDelay: 300 * time.Millisecond}
+	server := startStub(t, stub.handle)
+
+	config := NewClientConfig()
+	config.Addresses = []Address{server.address}
+	config.Logger = testLogger{t}
+	config.InvocationConfig.InvocationTimeout = 100 * time.Millisecond
+
+	client, err := NewHazelcastClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Shutdown()
+
+	ctx := context.Background()
+
+	queue, err := GetQueue[string](ctx, client, "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue.Put(ctx, "late")
+
+	item, err := queue.Take(ctx)
+	if err != nil || item != "late" {
+		t.Fatalf("got %v, %v", item, err)
+	}
+
+	// ctx still bounds the wait
+	timeout, cancel := context.WithTimeout(ctx, 50 * time.Millisecond)
+	defer cancel()
+
+	if _, err := queue.Take(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestQueueItemListener(t *testing.T) {
+
+	service, _ := NewSerializationService(nil)
+	item, _ := service.ToData("hello")
+
+	stub := &stubQueue{capacity: 1}
+	stub.events = []*ClientMessage{itemEventMessage(item, "member", ITEM_ADDED), itemEventMessage(nil, "member", ITEM_REMOVED)}
+
+	client, _ := newStubClient(t, stub.handle)
+	ctx := context.Background()
+
+	queue, err := GetQueue[string](ctx, client, "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan ItemEvent[string], 2)
+	id, err := queue.AddItemChannel(ctx, true, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ItemEvent[string]{
+		{Name: "events", Item: "hello", HasItem: true, MemberUuid: "member", EventType: ITEM_ADDED},
+		{Name: "events", MemberUuid: "member", EventType: ITEM_REMOVED},
+	}
+
+	for _, expected := range want {
+		select {
+		case event := <-events:
+			if event != expected {
+				t.Fatalf("got %+v, want %+v", event, expected)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("no item event")
+		}
+	}
+
+	if err := queue.RemoveItemListener(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+
+	if !reflect.DeepEqual(stub.removedListeners, []string{"item-registration"}) {
+		t.Fatalf("removed listeners: %v", stub.removedListeners)
+	}
+}
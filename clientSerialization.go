@@ -0,0 +1,361 @@
+package hz
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+/* Hazelcast serializer type ids, <= 0 are reserved */
+const (
+
+	TYPE_NULL = 0
+	TYPE_PORTABLE = -1
+	TYPE_DATA_SERIALIZABLE = -2
+	TYPE_BYTE = -3
+	TYPE_BOOLEAN = -4
+	TYPE_CHAR = -5
+	TYPE_SHORT = -6
+	TYPE_INTEGER = -7
+	TYPE_LONG = -8
+	TYPE_FLOAT = -9
+	TYPE_DOUBLE = -10
+	TYPE_STRING = -11
+	TYPE_BYTE_ARRAY = -12
+	TYPE_BOOLEAN_ARRAY = -13
+	TYPE_CHAR_ARRAY = -14
+	TYPE_SHORT_ARRAY = -15
+	TYPE_INTEGER_ARRAY = -16
+	TYPE_LONG_ARRAY = -17
+	TYPE_FLOAT_ARRAY = -18
+	TYPE_DOUBLE_ARRAY = -19
+	TYPE_STRING_ARRAY = -20
+	TYPE_JSON = -130
+)
+
+const (
+
+	DATA_PARTITION_HASH_OFFSET = 0
+	DATA_TYPE_OFFSET = 4
+	DATA_OFFSET = 8
+)
+
+var (
+	ErrSerializerNotFound = errors.New("No serializer found")
+	ErrInvalidData = errors.New("Invalid serialized data")
+)
+
+// Serialized form of a value: big endian partition hash, serializer type id, then the serializer payload
+type Data []byte
+
+// Build Data from a serializer type id and payload, the partition hash is left zero so the payload hash is used
+func NewData(typeId int32, payload []byte) Data {
+
+	data := make([]byte, DATA_OFFSET+len(payload))
+	binary.BigEndian.PutUint32(data[DATA_TYPE_OFFSET:], uint32(typeId))
+	copy(data[DATA_OFFSET:], payload)
+
+	return data
+}
+
+func (data Data) TypeId() int32 {
+
+	if len(data) < DATA_OFFSET {
+		return TYPE_NULL
+	}
+
+	return int32(binary.BigEndian.Uint32(data[DATA_TYPE_OFFSET:]))
+}
+
+func (data Data) Payload() []byte {
+
+	if len(data) < DATA_OFFSET {
+		return nil
+	}
+
+	return data[DATA_OFFSET:]
+}
+
+// The explicit partition hash if set, otherwise the murmur hash of the payload as the java client calculates it
+func (data Data) PartitionHash() int32 {
+
+	if len(data) < DATA_OFFSET {
+		return 0
+	}
+
+	if hash := int32(binary.BigEndian.Uint32(data[DATA_PARTITION_HASH_OFFSET:])); hash != 0 {
+		return hash
+	}
+
+	return hash32(data.Payload(), 0x01000193)
+}
+
+// The partition owning this Data when used as a key
+func (data Data) PartitionId(partitionCount int32) int32 {
+
+	if partitionCount <= 0 {
+		return -1
+	}
+
+	hash := data.PartitionHash()
+	if hash == INTEGER32_MIN_VALUE {
+		hash = 0
+	} else if hash < 0 {
+		hash = -hash
+	}
+
+	return hash % partitionCount
+}
+
+// Converts values of one type to and from bytes, written as a length prefixed byte array within Data
+type Serializer interface {
+
+	// Must be > 0 for custom and global serializers
+	TypeId() int32
+	Write(value interface{}) ([]byte, error)
+	Read(payload []byte) (interface{}, error)
+}
+
+type SerializationConfig struct {
+
+	customSerializers map[reflect.Type]Serializer
+	dataSerializableFactories map[int32]DataSerializableFactory
+	portableFactories map[int32]PortableFactory
+	classDefinitions []*ClassDefinition
+
+	// Class version of portables that are not a VersionedPortable
+	PortableVersion int32
+
+	// Used for values no built in or custom serializer handles
+	GlobalSerializer Serializer
+}
+
+func NewSerializationConfig() *SerializationConfig {
+
+	config := new(SerializationConfig)
+	config.customSerializers = make(map[reflect.Type]Serializer)
+	config.dataSerializableFactories = make(map[int32]DataSerializableFactory)
+	config.portableFactories = make(map[int32]PortableFactory)
+
+	return config
+}
+
+// Register the factory creating Portable instances for a factory id
+func (this *SerializationConfig) AddPortableFactory(factoryId int32, factory PortableFactory) {
+
+	if this.portableFactories == nil {
+		this.portableFactories = make(map[int32]PortableFactory)
+	}
+	this.portableFactories[factoryId] = factory
+}
+
+// Register a class definition up front, required for nil portable fields and empty portable arrays of classes not yet written
+func (this *SerializationConfig) AddClassDefinition(classDefinition *ClassDefinition) {
+
+	this.classDefinitions = append(this.classDefinitions, classDefinition)
+}
+
+// Register the factory creating IdentifiedDataSerializable instances for a factory id
+func (this *SerializationConfig) AddDataSerializableFactory(factoryId int32, factory DataSerializableFactory) {
+
+	if this.dataSerializableFactories == nil {
+		this.dataSerializableFactories = make(map[int32]DataSerializableFactory)
+	}
+	this.dataSerializableFactories[factoryId] = factory
+}
+
+// Register a serializer for values of the given type
+func (this *SerializationConfig) AddCustomSerializer(valueType reflect.Type, serializer Serializer) error {
+
+	if serializer.TypeId() <= 0 {
+		return errors.New(fmt.Sprintf("Custom serializer type id must be > 0, got: %d", serializer.TypeId()))
+	}
+
+	if this.customSerializers == nil {
+		this.customSerializers = make(map[reflect.Type]Serializer)
+	}
+	this.customSerializers[valueType] = serializer
+
+	return nil
+}
+
+// Safe for concurrent use, only portable class definitions are added once created
+type SerializationService struct {
+
+	config   *SerializationConfig
+	byType   map[reflect.Type]streamSerializer
+	byTypeId map[int32]streamSerializer
+
+	dataSerializableSerializer dataSerializableSerializer
+	portableSerializer         *portableSerializer
+	globalSerializer           streamSerializer
+}
+
+func NewSerializationService(config *SerializationConfig) (*SerializationService, error) {
+
+	if config == nil {
+		config = NewSerializationConfig()
+	}
+
+	service := new(SerializationService)
+	service.config = config
+	service.byType = make(map[reflect.Type]streamSerializer)
+	service.byTypeId = make(map[int32]streamSerializer)
+
+	for _, serializer := range builtinSerializers {
+		service.byTypeId[serializer.TypeId()] = serializer
+	}
+
+	service.dataSerializableSerializer = dataSerializableSerializer{factories: make(map[int32]DataSerializableFactory)}
+	service.dataSerializableSerializer.factories[PREDICATE_FACTORY_ID] = predicateFactory
+	for factoryId, factory := range config.dataSerializableFactories {
+		service.dataSerializableSerializer.factories[factoryId] = factory
+	}
+	service.byTypeId[TYPE_DATA_SERIALIZABLE] = service.dataSerializableSerializer
+
+	service.portableSerializer = &portableSerializer{context: newPortableContext(config.PortableVersion, config.portableFactories)}
+	for _, classDefinition := range config.classDefinitions {
+		if _, err := service.portableSerializer.context.register(classDefinition); err != nil {
+			return nil, err
+		}
+	}
+	service.byTypeId[TYPE_PORTABLE] = service.portableSerializer
+
+	for valueType, serializer := range config.customSerializers {
+		adapter := byteArraySerializerAdapter{serializer}
+		if err := service.register(adapter); err != nil {
+			return nil, err
+		}
+		service.byType[valueType] = adapter
+	}
+
+	if config.GlobalSerializer != nil {
+		service.globalSerializer = byteArraySerializerAdapter{config.GlobalSerializer}
+		if err := service.register(service.globalSerializer); err != nil {
+			return nil, err
+		}
+	}
+
+	return service, nil
+}
+
+// The serialization version presented to members during authentication
+func (this *SerializationService) Version() uint8 {
+
+	return SERIALIZATION_VERSION
+}
+
+func (this *SerializationService) register(serializer streamSerializer) error {
+
+	typeId := serializer.TypeId()
+
+	if typeId <= 0 {
+		return errors.New(fmt.Sprintf("Serializer type id must be > 0, got: %d", typeId))
+	}
+
+	if _, ok := this.byTypeId[typeId]; ok {
+		return errors.New(fmt.Sprintf("Serializer type id %d is already registered", typeId))
+	}
+	this.byTypeId[typeId] = serializer
+
+	return nil
+}
+
+// Serialize a value, nil serializes to nil Data and Data is returned unchanged
+func (this *SerializationService) ToData(value interface{}) (Data, error) {
+
+	if value == nil {
+		return nil, nil
+	}
+
+	if data, ok := value.(Data); ok {
+		return data, nil
+	}
+
+	serializer := this.serializerFor(value)
+	if serializer == nil {
+		return nil, fmt.Errorf("%w for type: %T", ErrSerializerNotFound, value)
+	}
+
+	// Written with the header as stream positions, such as portable field offsets, are relative to the start of Data
+	output := NewObjectDataOutput(this)
+	output.WriteInt32(0)
+	output.WriteInt32(serializer.TypeId())
+
+	if err := serializer.write(output, value); err != nil {
+		return nil, err
+	}
+
+	return Data(output.ToBuffer()), nil
+}
+
+// Deserialize Data using the serializer registered for its type id, nil Data deserializes to nil
+func (this *SerializationService) ToObject(data Data) (interface{}, error) {
+
+	if data == nil {
+		return nil, nil
+	}
+
+	if len(data) < DATA_OFFSET {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidData, len(data))
+	}
+
+	typeId := data.TypeId()
+
+	serializer, ok := this.byTypeId[typeId]
+	if !ok {
+		return nil, fmt.Errorf("%w for type id: %d", ErrSerializerNotFound, typeId)
+	}
+
+	input := NewObjectDataInput(data, this)
+	input.SetPosition(DATA_OFFSET)
+
+	value, err := serializer.read(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, input.Error()
+}
+
+// Assert a deserialized value to T. Go ints are written as a java Long, so an int64 converts back to int when T is int
+func castObject[T any](object interface{}) (T, bool) {
+
+	if result, ok := object.(T); ok {
+		return result, true
+	}
+
+	var result T
+
+	if v, ok := object.(int64); ok {
+		if p, ok := any(&result).(*int); ok {
+			*p = int(v)
+			return result, true
+		}
+	}
+
+	return result, false
+}
+
+// Lookup order follows the java client: identified data serializable, portable, built in types, custom serializers then the global serializer
+func (this *SerializationService) serializerFor(value interface{}) streamSerializer {
+
+	if _, ok := value.(IdentifiedDataSerializable); ok {
+		return this.dataSerializableSerializer
+	}
+
+	if _, ok := value.(Portable); ok {
+		return this.portableSerializer
+	}
+
+	if serializer := builtinSerializerFor(value); serializer != nil {
+		return serializer
+	}
+
+	if serializer, ok := this.byType[reflect.TypeOf(value)]; ok {
+		return serializer
+	}
+
+	return this.globalSerializer
+}
@@ -0,0 +1,486 @@
+package hz
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+/*
+Built in serializers for the java compatible types, all big endian:
+
+	nil        NULL            empty
+	uint8      BYTE            1 byte
+	bool       BOOLEAN         1 byte
+	uint16     CHAR            2 bytes, a java char
+	int16      SHORT           2 bytes
+	int32      INTEGER         4 bytes
+	int64      LONG            8 bytes, also used for int
+	float32    FLOAT           4 bytes IEEE 754
+	float64    DOUBLE          8 bytes IEEE 754
+	string     STRING          java writeUTF, a 4 byte utf-16 unit count then 1 to 3 bytes per unit
+	[]byte     BYTE_ARRAY      4 byte length then the bytes
+	[]T        T_ARRAY         4 byte length then the elements, for each of the types above
+
+	HazelcastJsonValue  JSON   java writeUTF of the JSON
+*/
+
+// Serializers writing straight to the stream, as the java StreamSerializer
+type streamSerializer interface {
+
+	TypeId() int32
+	write(output *ObjectDataOutput, value interface{}) error
+	read(input *ObjectDataInput) (interface{}, error)
+}
+
+var builtinSerializers = []streamSerializer{
+	nullSerializer{},
+	byteSerializer{},
+	boolSerializer{},
+	charSerializer{},
+	shortSerializer{},
+	integerSerializer{},
+	longSerializer{},
+	floatSerializer{},
+	doubleSerializer{},
+	stringSerializer{},
+	byteArraySerializer{},
+	boolArraySerializer{},
+	charArraySerializer{},
+	shortArraySerializer{},
+	integerArraySerializer{},
+	longArraySerializer{},
+	floatArraySerializer{},
+	doubleArraySerializer{},
+	stringArraySerializer{},
+	jsonValueSerializer{},
+}
+
+func builtinSerializerFor(value interface{}) streamSerializer {
+
+	switch value.(type) {
+	case uint8:
+		return byteSerializer{}
+	case bool:
+		return boolSerializer{}
+	case uint16:
+		return charSerializer{}
+	case int16:
+		return shortSerializer{}
+	case int32:
+		return integerSerializer{}
+	case int64, int:
+		return longSerializer{}
+	case float32:
+		return floatSerializer{}
+	case float64:
+		return doubleSerializer{}
+	case string:
+		return stringSerializer{}
+	case []byte:
+		return byteArraySerializer{}
+	case []bool:
+		return boolArraySerializer{}
+	case []uint16:
+		return charArraySerializer{}
+	case []int16:
+		return shortArraySerializer{}
+	case []int32:
+		return integerArraySerializer{}
+	case []int64:
+		return longArraySerializer{}
+	case []float32:
+		return floatArraySerializer{}
+	case []float64:
+		return doubleArraySerializer{}
+	case []string:
+		return stringArraySerializer{}
+	case HazelcastJsonValue:
+		return jsonValueSerializer{}
+	}
+
+	return nil
+}
+
+// Adapts a custom byte based Serializer, its bytes are written as a length prefixed byte array like the java ByteArraySerializer
+type byteArraySerializerAdapter struct {
+
+	serializer Serializer
+}
+
+func (this byteArraySerializerAdapter) TypeId() int32 { return this.serializer.TypeId() }
+
+func (this byteArraySerializerAdapter) write(output *ObjectDataOutput, value interface{}) error {
+
+	bytes, err := this.serializer.Write(value)
+	if err != nil {
+		return err
+	}
+	output.WriteByteArray(bytes)
+
+	return nil
+}
+
+func (this byteArraySerializerAdapter) read(input *ObjectDataInput) (interface{}, error) {
+
+	bytes := input.ReadByteArray()
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+
+	return this.serializer.Read(bytes)
+}
+
+type nullSerializer struct{}
+
+func (nullSerializer) TypeId() int32 { return TYPE_NULL }
+
+func (nullSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	return nil
+}
+
+func (nullSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return nil, nil
+}
+
+type byteSerializer struct{}
+
+func (byteSerializer) TypeId() int32 { return TYPE_BYTE }
+
+func (byteSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteUInt8(value.(uint8))
+	return nil
+}
+
+func (byteSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadUInt8(), input.Error()
+}
+
+type boolSerializer struct{}
+
+func (boolSerializer) TypeId() int32 { return TYPE_BOOLEAN }
+
+func (boolSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteBool(value.(bool))
+	return nil
+}
+
+func (boolSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadBool(), input.Error()
+}
+
+type charSerializer struct{}
+
+func (charSerializer) TypeId() int32 { return TYPE_CHAR }
+
+func (charSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteUInt16(value.(uint16))
+	return nil
+}
+
+func (charSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadUInt16(), input.Error()
+}
+
+type shortSerializer struct{}
+
+func (shortSerializer) TypeId() int32 { return TYPE_SHORT }
+
+func (shortSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteInt16(value.(int16))
+	return nil
+}
+
+func (shortSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadInt16(), input.Error()
+}
+
+type integerSerializer struct{}
+
+func (integerSerializer) TypeId() int32 { return TYPE_INTEGER }
+
+func (integerSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteInt32(value.(int32))
+	return nil
+}
+
+func (integerSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadInt32(), input.Error()
+}
+
+type longSerializer struct{}
+
+func (longSerializer) TypeId() int32 { return TYPE_LONG }
+
+func (longSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	switch value := value.(type) {
+	case int:
+		output.WriteInt64(int64(value))
+	default:
+		output.WriteInt64(value.(int64))
+	}
+	return nil
+}
+
+func (longSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadInt64(), input.Error()
+}
+
+type floatSerializer struct{}
+
+func (floatSerializer) TypeId() int32 { return TYPE_FLOAT }
+
+func (floatSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteFloat32(value.(float32))
+	return nil
+}
+
+func (floatSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadFloat32(), input.Error()
+}
+
+type doubleSerializer struct{}
+
+func (doubleSerializer) TypeId() int32 { return TYPE_DOUBLE }
+
+func (doubleSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteFloat64(value.(float64))
+	return nil
+}
+
+func (doubleSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadFloat64(), input.Error()
+}
+
+type stringSerializer struct{}
+
+func (stringSerializer) TypeId() int32 { return TYPE_STRING }
+
+func (stringSerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteUTF(value.(string))
+	return nil
+}
+
+func (stringSerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadUTF(), input.Error()
+}
+
+type byteArraySerializer struct{}
+
+func (byteArraySerializer) TypeId() int32 { return TYPE_BYTE_ARRAY }
+
+func (byteArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteByteArray(value.([]byte))
+	return nil
+}
+
+func (byteArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadByteArray(), input.Error()
+}
+
+type boolArraySerializer struct{}
+
+func (boolArraySerializer) TypeId() int32 { return TYPE_BOOLEAN_ARRAY }
+
+func (boolArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteBoolArray(value.([]bool))
+	return nil
+}
+
+func (boolArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadBoolArray(), input.Error()
+}
+
+type charArraySerializer struct{}
+
+func (charArraySerializer) TypeId() int32 { return TYPE_CHAR_ARRAY }
+
+func (charArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteUInt16Array(value.([]uint16))
+	return nil
+}
+
+func (charArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadUInt16Array(), input.Error()
+}
+
+type shortArraySerializer struct{}
+
+func (shortArraySerializer) TypeId() int32 { return TYPE_SHORT_ARRAY }
+
+func (shortArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteInt16Array(value.([]int16))
+	return nil
+}
+
+func (shortArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadInt16Array(), input.Error()
+}
+
+type integerArraySerializer struct{}
+
+func (integerArraySerializer) TypeId() int32 { return TYPE_INTEGER_ARRAY }
+
+func (integerArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteInt32Array(value.([]int32))
+	return nil
+}
+
+func (integerArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadInt32Array(), input.Error()
+}
+
+type longArraySerializer struct{}
+
+func (longArraySerializer) TypeId() int32 { return TYPE_LONG_ARRAY }
+
+func (longArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteInt64Array(value.([]int64))
+	return nil
+}
+
+func (longArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadInt64Array(), input.Error()
+}
+
+type floatArraySerializer struct{}
+
+func (floatArraySerializer) TypeId() int32 { return TYPE_FLOAT_ARRAY }
+
+func (floatArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteFloat32Array(value.([]float32))
+	return nil
+}
+
+func (floatArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadFloat32Array(), input.Error()
+}
+
+type doubleArraySerializer struct{}
+
+func (doubleArraySerializer) TypeId() int32 { return TYPE_DOUBLE_ARRAY }
+
+func (doubleArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteFloat64Array(value.([]float64))
+	return nil
+}
+
+func (doubleArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadFloat64Array(), input.Error()
+}
+
+type stringArraySerializer struct{}
+
+func (stringArraySerializer) TypeId() int32 { return TYPE_STRING_ARRAY }
+
+func (stringArraySerializer) write(output *ObjectDataOutput, value interface{}) error {
+
+	output.WriteUTFArray(value.([]string))
+	return nil
+}
+
+func (stringArraySerializer) read(input *ObjectDataInput) (interface{}, error) {
+
+	return input.ReadUTFArray(), input.Error()
+}
+
+// Append a string as java writeUTF does, the utf-16 unit count then each unit as 1 to 3 bytes
+func appendUTF(buffer []byte, str string) []byte {
+
+	units := utf16.Encode([]rune(str))
+
+	length := uint32(len(units))
+	buffer = append(buffer, byte(length>>24), byte(length>>16), byte(length>>8), byte(length))
+
+	for _, c := range units {
+		switch {
+		case c <= 0x007f:
+			buffer = append(buffer, byte(c))
+		case c <= 0x07ff:
+			buffer = append(buffer, byte(0xc0|(c>>6)&0x1f), byte(0x80|c&0x3f))
+		default:
+			buffer = append(buffer, byte(0xe0|(c>>12)&0x0f), byte(0x80|(c>>6)&0x3f), byte(0x80|c&0x3f))
+		}
+	}
+
+	return buffer
+}
+
+// Read a java writeUTF string, returning the string and the number of bytes consumed
+func readUTF(buffer []byte) (string, int, error) {
+
+	if len(buffer) < INT_SIZE_IN_BYTES {
+		return "", 0, fmt.Errorf("%w: string length missing", ErrInvalidData)
+	}
+
+	length := int32(uint32(buffer[0])<<24 | uint32(buffer[1])<<16 | uint32(buffer[2])<<8 | uint32(buffer[3]))
+	position := INT_SIZE_IN_BYTES
+
+	if length <= 0 {
+		return "", position, nil
+	}
+
+	if int(length) > len(buffer)-position {
+		return "", position, fmt.Errorf("%w: string of %d characters truncated", ErrInvalidData, length)
+	}
+
+	units := make([]uint16, length)
+	for i := range units {
+		if position >= len(buffer) {
+			return "", position, fmt.Errorf("%w: string truncated", ErrInvalidData)
+		}
+
+		b := buffer[position]
+		switch {
+		case b&0x80 == 0:
+			units[i] = uint16(b)
+			position++
+		case b&0xe0 == 0xc0 && position+1 < len(buffer):
+			units[i] = uint16(b&0x1f)<<6 | uint16(buffer[position+1]&0x3f)
+			position += 2
+		case b&0xf0 == 0xe0 && position+2 < len(buffer):
+			units[i] = uint16(b&0x0f)<<12 | uint16(buffer[position+1]&0x3f)<<6 | uint16(buffer[position+2]&0x3f)
+			position += 3
+		default:
+			return "", position, errors.New(fmt.Sprintf("Malformed utf byte: 0x%02x", b))
+		}
+	}
+
+	return string(utf16.Decode(units)), position, nil
+}
@@ -0,0 +1,101 @@
+package hz
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinSerializerRoundTrip(t *testing.T) {
+
+	service, err := NewSerializationService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		typeId int32
+		want   interface{}
+	}{
+		{"byte", uint8(200), TYPE_BYTE, uint8(200)},
+		{"bool", true, TYPE_BOOLEAN, true},
+		{"char", uint16('x'), TYPE_CHAR, uint16('x')},
+		{"short", int16(-12), TYPE_SHORT, int16(-12)},
+		{"integer", int32(-123456), TYPE_INTEGER, int32(-123456)},
+		{"long", int64(1) << 40, TYPE_LONG, int64(1) << 40},
+		{"int as long", int(-42), TYPE_LONG, int64(-42)},
+		{"float", float32(1.5), TYPE_FLOAT, float32(1.5)},
+		{"double", float64(-2.25), TYPE_DOUBLE, float64(-2.25)},
+		{"string", "héllo 😀", TYPE_STRING, "héllo 😀"},
+		{"byte array", []byte{1, 2, 3}, TYPE_BYTE_ARRAY, []byte{1, 2, 3}},
+		{"bool array", []bool{true, false}, TYPE_BOOLEAN_ARRAY, []bool{true, false}},
+		{"char array", []uint16{'a', 'b'}, TYPE_CHAR_ARRAY, []uint16{'a', 'b'}},
+		{"short array", []int16{1, -1}, TYPE_SHORT_ARRAY, []int16{1, -1}},
+		{"integer array", []int32{1, -1}, TYPE_INTEGER_ARRAY, []int32{1, -1}},
+		{"long array", []int64{1, -1}, TYPE_LONG_ARRAY, []int64{1, -1}},
+		{"float array", []float32{1, -1}, TYPE_FLOAT_ARRAY, []float32{1, -1}},
+		{"double array", []float64{1, -1}, TYPE_DOUBLE_ARRAY, []float64{1, -1}},
+		{"string array", []string{"a", "b"}, TYPE_STRING_ARRAY, []string{"a", "b"}},
+		{"json", NewHazelcastJsonValue(`{"a":1}`), TYPE_JSON, NewHazelcastJsonValue(`{"a":1}`)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			data, err := service.ToData(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.TypeId() != test.typeId {
+				t.Fatalf("type id: got %d, want %d", data.TypeId(), test.typeId)
+			}
+
+			object, err := service.ToObject(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(object, test.want) {
+				t.Fatalf("got %#v, want %#v", object, test.want)
+			}
+		})
+	}
+}
+
+func TestNilRoundTrip(t *testing.T) {
+
+	service, _ := NewSerializationService(nil)
+
+	data, err := service.ToData(nil)
+	if err != nil || data != nil {
+		t.Fatalf("got %v, %v", data, err)
+	}
+
+	object, err := service.ToObject(nil)
+	if err != nil || object != nil {
+		t.Fatalf("got %v, %v", object, err)
+	}
+}
+
+func TestCastObjectIntFromLong(t *testing.T) {
+
+	service, _ := NewSerializationService(nil)
+
+	data, _ := service.ToData(7)
+	object, _ := service.ToObject(data)
+
+	value, ok := castObject[int](object)
+	if !ok || value != 7 {
+		t.Fatalf("got %v, %v", value, ok)
+	}
+
+	if _, ok := castObject[int32](object); ok {
+		t.Fatal("a Long must not convert to int32")
+	}
+
+	if _, ok := castObject[int]("7"); ok {
+		t.Fatal("a string must not convert to int")
+	}
+}
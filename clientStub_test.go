@@ -0,0 +1,425 @@
+package hz
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+/*
+	A single member cluster for tests. The stub answers authentication, the partition table, membership and partition listeners,
+	pings and proxy creation itself, any other request goes to the test handler which returns the response messages to send.
+	Membership events can be pushed at any time once the client has added its membership listener
+ */
+
+type stubHandler func(request *ClientMessage) []*ClientMessage
+
+type stubServer struct {
+
+	address Address
+
+	// The protocol header of each accepted connection
+	headers chan []byte
+
+	mutex       sync.Mutex
+	connections []net.Conn
+
+	// Responses and pushed events are written whole
+	writeMutex sync.Mutex
+
+	memberListeners int32
+
+	// Where the last membership listener was added, events pushed by the test go there
+	membershipConnection    net.Conn
+	membershipCorrelationId int64
+}
+
+func startStub(t *testing.T, handler stubHandler) *stubServer {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return serveStub(t, listener, handler)
+}
+
+// Serve on the listener until the test ends, used directly for TLS listeners
+func serveStub(t *testing.T, listener net.Listener, handler stubHandler) *stubServer {
+
+	server := &stubServer{headers: make(chan []byte, 16)}
+	server.address = Address{Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port}
+
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			server.mutex.Lock()
+			server.connections = append(server.connections, connection)
+			server.mutex.Unlock()
+
+			go server.serve(connection, handler)
+		}
+	}()
+
+	t.Cleanup(func() {
+		listener.Close()
+		server.closeConnections()
+	})
+
+	return server
+}
+
+func (this *stubServer) serve(connection net.Conn, handler stubHandler) {
+
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(connection, header); err != nil {
+		return
+	}
+
+	select {
+	case this.headers <- header:
+	default:
+	}
+
+	fragments := make(map[int64]*ClientMessage)
+
+	for {
+		frame, err := readStubFrame(connection)
+		if err != nil {
+			return
+		}
+
+		request := frame
+		if frame.HasFlags(BEGIN_END_FLAG) != BEGIN_END_FLAG {
+			if frame.HasFlags(BEGIN_FLAG) != 0 {
+				fragments[frame.GetCorrelationId()] = frame
+				continue
+			}
+			request = fragments[frame.GetCorrelationId()]
+			request.Accumulate(frame)
+			if !request.IsComplete() {
+				continue
+			}
+			delete(fragments, frame.GetCorrelationId())
+		}
+
+		responses := handler(request)
+		if responses == nil {
+			responses = this.defaultResponses(connection, request)
+		}
+
+		for _, response := range responses {
+			if err := this.write(connection, request.GetCorrelationId(), response); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Small frames so responses are also reassembled by the client
+func (this *stubServer) write(connection net.Conn, correlationId int64, response *ClientMessage) error {
+
+	this.writeMutex.Lock()
+	defer this.writeMutex.Unlock()
+
+	response.SetCorrelationId(uint64(correlationId))
+	for _, fragment := range response.Fragment(64) {
+		if _, err := connection.Write(fragment.Buffer[:fragment.GetFrameLength()]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Send a membership event to the client as the member would on a topology change
+func (this *stubServer) pushMembershipEvent(t *testing.T, event *ClientMessage) {
+
+	t.Helper()
+
+	this.mutex.Lock()
+	connection, correlationId := this.membershipConnection, this.membershipCorrelationId
+	this.mutex.Unlock()
+
+	if connection == nil {
+		t.Fatal("no membership listener added")
+	}
+	if err := this.write(connection, correlationId, event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (this *stubServer) defaultResponses(connection net.Conn, request *ClientMessage) []*ClientMessage {
+
+	switch request.GetMessageType() {
+	case CLIENT_AUTHENTICATION, CLIENT_AUTHENTICATIONCUSTOM:
+		return []*ClientMessage{authResponse(this.address, 1)}
+	case CLIENT_GETPARTITIONS:
+		return []*ClientMessage{partitionsResponse(this.address)}
+	case CLIENT_ADDMEMBERSHIPLISTENER:
+		atomic.AddInt32(&this.memberListeners, 1)
+		this.mutex.Lock()
+		this.membershipConnection, this.membershipCorrelationId = connection, request.GetCorrelationId()
+		this.mutex.Unlock()
+		return []*ClientMessage{stringResponse("membership-registration"), memberListEvent(this.address)}
+	case CLIENT_ADDPARTITIONLISTENER, CLIENT_PING, CLIENT_CREATEPROXY, CLIENT_DESTROYPROXY:
+		return []*ClientMessage{voidResponse()}
+	}
+
+	return nil
+}
+
+// Close the accepted connections, the client sees the member go away
+func (this *stubServer) closeConnections() {
+
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	for _, connection := range this.connections {
+		connection.Close()
+	}
+	this.connections = nil
+}
+
+func readStubFrame(connection net.Conn) (*ClientMessage, error) {
+
+	length := make([]byte, INT_SIZE_IN_BYTES)
+	if _, err := io.ReadFull(connection, length); err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, binary.LittleEndian.Uint32(length))
+	copy(buffer, length)
+	if _, err := io.ReadFull(connection, buffer[INT_SIZE_IN_BYTES:]); err != nil {
+		return nil, err
+	}
+
+	return CreateForDecode(buffer), nil
+}
+
+// A client connected to a stub member, shut down when the test ends
+func newStubClient(t *testing.T, handler stubHandler) (*HazelcastClient, *stubServer) {
+
+	return newConfiguredStubClient(t, handler, func(*ClientConfig) {})
+}
+
+// As newStubClient with the default configuration changed by configure
+func newConfiguredStubClient(t *testing.T, handler stubHandler, configure func(*ClientConfig)) (*HazelcastClient, *stubServer) {
+
+	server := startStub(t, handler)
+
+	config := NewClientConfig()
+	config.Addresses = []Address{server.address}
+	config.Logger = testLogger{t}
+	configure(config)
+
+	client, err := NewHazelcastClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Shutdown)
+
+	return client, server
+}
+
+type testLogger struct {
+
+	t *testing.T
+}
+
+func (this testLogger) Trace(format string, v ...interface{}) {}
+func (this testLogger) Info(format string, v ...interface{})  { this.t.Logf(format, v...) }
+func (this testLogger) Warn(format string, v ...interface{})  { this.t.Logf("WARN "+format, v...) }
+func (this testLogger) Error(format string, v ...interface{}) { this.t.Logf("ERROR "+format, v...) }
+func (this testLogger) Fatal(format string, v ...interface{}) { this.t.Errorf("FATAL "+format, v...) }
+
+/*
+	Response messages
+ */
+
+func newStubResponse(messageType uint16, payloadSize int) *ClientMessage {
+
+	response := CreateForEncode(payloadSize)
+	response.SetMessageType(messageType)
+	response.SetFlags(BEGIN_END_FLAG)
+
+	return response
+}
+
+func finishStubResponse(response *ClientMessage) *ClientMessage {
+
+	response.UpdateFrameLength()
+
+	return response
+}
+
+func voidResponse() *ClientMessage {
+
+	return finishStubResponse(newStubResponse(RESPONSE_VOID, 0))
+}
+
+func boolResponse(value bool) *ClientMessage {
+
+	response := newStubResponse(RESPONSE_BOOLEAN, BOOLEAN_SIZE_IN_BYTES)
+	response.AppendBool(value)
+
+	return finishStubResponse(response)
+}
+
+func intResponse(value int32) *ClientMessage {
+
+	response := newStubResponse(RESPONSE_INTEGER, INT_SIZE_IN_BYTES)
+	response.AppendInt(int(value))
+
+	return finishStubResponse(response)
+}
+
+func stringResponse(value string) *ClientMessage {
+
+	response := newStubResponse(RESPONSE_STRING, CalculateSizeStr(&value))
+	response.AppendStr(&value)
+
+	return finishStubResponse(response)
+}
+
+// Null when data is nil
+func dataResponse(data Data) *ClientMessage {
+
+	response := newStubResponse(RESPONSE_DATA, CalculateSizeNullableData(data))
+	response.AppendNullableData(data)
+
+	return finishStubResponse(response)
+}
+
+func listResponse(list []Data) *ClientMessage {
+
+	response := newStubResponse(RESPONSE_LIST_DATA, CalculateSizeDataList(list))
+	response.AppendDataList(list)
+
+	return finishStubResponse(response)
+}
+
+// A server exception without message, stack trace or cause
+func errorResponse(errorCode int32, className string) *ClientMessage {
+
+	response := newStubResponse(RESPONSE_EXCEPTION, INT_SIZE_IN_BYTES + CalculateSizeStr(&className) + BOOLEAN_SIZE_IN_BYTES + 2 * INT_SIZE_IN_BYTES +
+		BOOLEAN_SIZE_IN_BYTES)
+
+	response.AppendInt(int(errorCode))
+	response.AppendStr(&className)
+	// message
+	response.AppendBool(true)
+	// stack trace
+	response.AppendInt(0)
+	// cause
+	response.AppendInt(0)
+	response.AppendBool(true)
+
+	return finishStubResponse(response)
+}
+
+func entryListResponse(list []DataEntry) *ClientMessage {
+
+	response := newStubResponse(RESPONSE_LIST_ENTRY, CalculateSizeEntryList(list))
+	response.AppendEntryList(list)
+
+	return finishStubResponse(response)
+}
+
+func authResponse(address Address, serializationVersion uint8) *ClientMessage {
+
+	uuid := "stub-uuid"
+	response := newStubResponse(RESPONSE_AUTHENTICATION, BYTE_SIZE_IN_BYTES + BOOLEAN_SIZE_IN_BYTES + CalculateSizeStr(&address.Host) + INT_SIZE_IN_BYTES +
+		2 * (BOOLEAN_SIZE_IN_BYTES + CalculateSizeStr(&uuid)) + BYTE_SIZE_IN_BYTES)
+
+	response.AppendByte(AUTHENTICATED)
+	response.AppendBool(false)
+	response.AppendStr(&address.Host)
+	response.AppendInt(address.Port)
+	response.AppendBool(false)
+	response.AppendStr(&uuid)
+	response.AppendBool(false)
+	response.AppendStr(&uuid)
+	response.AppendByte(serializationVersion)
+
+	return finishStubResponse(response)
+}
+
+// Every partition owned by the stub member
+func partitionsResponse(address Address) *ClientMessage {
+
+	const partitionCount = 271
+
+	partitionIds := make([]int32, partitionCount)
+	for partitionId := range partitionIds {
+		partitionIds[partitionId] = int32(partitionId)
+	}
+
+	return partitionTableResponse([]MemberPartitions{{Address: address, PartitionIds: partitionIds}})
+}
+
+func partitionTableResponse(members []MemberPartitions) *ClientMessage {
+
+	payloadSize := INT_SIZE_IN_BYTES
+	for _, member := range members {
+		payloadSize += CalculateSizeStr(&member.Address.Host) + 2 * INT_SIZE_IN_BYTES + len(member.PartitionIds) * INT_SIZE_IN_BYTES
+	}
+	response := newStubResponse(RESPONSE_PARTITIONS, payloadSize + INT_SIZE_IN_BYTES)
+
+	response.AppendInt(len(members))
+	for _, member := range members {
+		response.AppendStr(&member.Address.Host)
+		response.AppendInt(member.Address.Port)
+		response.AppendInt(len(member.PartitionIds))
+		for _, partitionId := range member.PartitionIds {
+			response.AppendInt(int(partitionId))
+		}
+	}
+	// partition state version
+	response.AppendInt(1)
+
+	return finishStubResponse(response)
+}
+
+func memberListEvent(address Address) *ClientMessage {
+
+	uuid := "stub-member"
+	event := newStubResponse(EVENT_MEMBERLIST, 2 * INT_SIZE_IN_BYTES + CalculateSizeStr(&address.Host) + CalculateSizeStr(&uuid) +
+		BOOLEAN_SIZE_IN_BYTES + INT_SIZE_IN_BYTES)
+	event.SetFlags(BEGIN_END_FLAG | LISTENER_FLAG)
+
+	event.AppendInt(1)
+	event.AppendStr(&address.Host)
+	event.AppendInt(address.Port)
+	event.AppendStr(&uuid)
+	event.AppendBool(false)
+	// attributes
+	event.AppendInt(0)
+
+	return finishStubResponse(event)
+}
+
+// EVENT_MEMBER for a member without attributes
+func memberEvent(address Address, uuid string, eventType int32) *ClientMessage {
+
+	event := newStubResponse(EVENT_MEMBER, CalculateSizeStr(&address.Host) + INT_SIZE_IN_BYTES + CalculateSizeStr(&uuid) + BOOLEAN_SIZE_IN_BYTES +
+		2 * INT_SIZE_IN_BYTES)
+	event.SetFlags(BEGIN_END_FLAG | LISTENER_FLAG)
+
+	event.AppendStr(&address.Host)
+	event.AppendInt(address.Port)
+	event.AppendStr(&uuid)
+	event.AppendBool(false)
+	// attributes
+	event.AppendInt(0)
+	event.AppendInt(int(eventType))
+
+	return finishStubResponse(event)
+}
@@ -0,0 +1,89 @@
+package hz
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLS settings for member connections. A nil *TLSConfig means plain TCP
+type TLSConfig struct {
+
+	// Trusted certificate authorities, the system pool when nil
+	RootCAs *x509.CertPool
+
+	// Presented to the member for mutual TLS
+	ClientCertificate *tls.Certificate
+
+	// Verified against the member certificate, defaults to the host being dialled
+	ServerName string
+
+	// tls.VersionTLS12 when zero
+	MinVersion uint16
+
+	// Accept any member certificate. For development only!
+	InsecureSkipVerify bool
+}
+
+func NewTLSConfig() *TLSConfig {
+
+	config := new(TLSConfig)
+	config.MinVersion = tls.VersionTLS12
+
+	return config
+}
+
+// Add the PEM encoded certificates in the file to RootCAs
+func (this *TLSConfig) LoadCAFile(caFile string) error {
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return err
+	}
+
+	if this.RootCAs == nil {
+		this.RootCAs = x509.NewCertPool()
+	}
+	if !this.RootCAs.AppendCertsFromPEM(pem) {
+		return errors.New(fmt.Sprintf("No certificates found in CA file: %s", caFile))
+	}
+
+	return nil
+}
+
+// Load the PEM encoded client certificate and private key used for mutual TLS
+func (this *TLSConfig) LoadClientCertificate(certFile string, keyFile string) error {
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	this.ClientCertificate = &certificate
+
+	return nil
+}
+
+func (this *TLSConfig) toTLS(host string) *tls.Config {
+
+	config := &tls.Config{
+		RootCAs:            this.RootCAs,
+		ServerName:         this.ServerName,
+		MinVersion:         this.MinVersion,
+		InsecureSkipVerify: this.InsecureSkipVerify,
+	}
+
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if this.ClientCertificate != nil {
+		config.Certificates = []tls.Certificate{*this.ClientCertificate}
+	}
+
+	return config
+}
@@ -0,0 +1,183 @@
+package hz
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// A certificate authority generated for the test
+type testCA struct {
+
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{certificate: certificate, key: key}
+}
+
+func (this *testCA) pool() *x509.CertPool {
+
+	pool := x509.NewCertPool()
+	pool.AddCert(this.certificate)
+
+	return pool
+}
+
+// A leaf certificate signed by the CA, for a member when dnsName is set, otherwise for a client
+func (this *testCA) issue(t *testing.T, dnsName string) tls.Certificate {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if dnsName != "" {
+		template.DNSNames = []string{dnsName}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, this.certificate, &key.PublicKey, this.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// A stub member behind a TLS listener
+func startTLSStub(t *testing.T, config *tls.Config) *stubServer {
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return serveStub(t, listener, func(request *ClientMessage) []*ClientMessage { return nil })
+}
+
+func connectTLS(t *testing.T, address Address, config *TLSConfig) (*ClientConnection, error) {
+
+	manager := &ClientConnectionManager{Logger: testLogger{t}, TLSConfig: config, AuthenticationTimeout: 5 * time.Second}
+	t.Cleanup(manager.CloseAll)
+
+	promise := manager.GetOrConnect(address, "dev", "dev-pass")
+
+	select {
+	case connection := <-promise.SuccessChannel:
+		return connection.(*ClientConnection), nil
+	case err := <-promise.FailureChannel:
+		return nil, err
+	}
+}
+
+func TestTLSConnection(t *testing.T) {
+
+	memberCA := newTestCA(t, "member-ca")
+	clientCA := newTestCA(t, "client-ca")
+	otherCA := newTestCA(t, "other-ca")
+
+	memberCertificate := memberCA.issue(t, "member.hz")
+	clientCertificate := clientCA.issue(t, "")
+
+	plain := startTLSStub(t, &tls.Config{Certificates: []tls.Certificate{memberCertificate}})
+	mutual := startTLSStub(t, &tls.Config{
+		Certificates: []tls.Certificate{memberCertificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCA.pool(),
+	})
+
+	tests := []struct {
+		name      string
+		server    *stubServer
+		config    *TLSConfig
+		wantError func(error) bool
+	}{
+		{"tls", plain, &TLSConfig{RootCAs: memberCA.pool(), ServerName: "member.hz"}, nil},
+		{"mutual tls", mutual, &TLSConfig{RootCAs: memberCA.pool(), ServerName: "member.hz", ClientCertificate: &clientCertificate}, nil},
+		{"untrusted ca", plain, &TLSConfig{RootCAs: otherCA.pool(), ServerName: "member.hz"}, func(err error) bool {
+			var unknownAuthority x509.UnknownAuthorityError
+			return errors.As(err, &unknownAuthority)
+		}},
+		{"wrong server name", plain, &TLSConfig{RootCAs: memberCA.pool(), ServerName: "other.hz"}, func(err error) bool {
+			var hostname x509.HostnameError
+			return errors.As(err, &hostname)
+		}},
+		{"mutual tls without client certificate", mutual, &TLSConfig{RootCAs: memberCA.pool(), ServerName: "member.hz"}, func(err error) bool {
+			return err != nil
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			connection, err := connectTLS(t, test.server.address, test.config)
+
+			if test.wantError != nil {
+				if err == nil || !test.wantError(err) {
+					t.Fatalf("got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case header := <-test.server.headers:
+				if string(header) != "CB2" {
+					t.Fatalf("protocol header: got %q, want CB2", header)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no protocol header read after the handshake")
+			}
+
+			if err := SendPing(connection); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
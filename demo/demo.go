@@ -2,77 +2,88 @@ package main
 
 import (
 	".."
+	"context"
 	"time"
 )
 
 var (
-	con *hz.ClientConnection
+	client *hz.HazelcastClient
 	logger *StdoutLogger
-	pingStopper chan bool
 )
 
-func startPinger() {
-
-	pingTicker := time.NewTicker(10 * time.Second)
-
-	go func() {
-
-		for {
-			select {
-			case <-pingStopper:
-				logger.Warn("Bus pinger notified to stop")
-				con.Close()
-			case <-pingTicker.C:
-				hz.SendPing(con)
-			}
-		}
-	}()
-}
-
 func main() {
 
-	var cm hz.ClientConnectionManager
-
 	logger = NewLogger()
-	pingStopper = make(chan bool)
 
 	logger.Info("demo start")
 
-	hzAdd := hz.Address{
-		Host: "localhost",
-		Port: 5900,
-	}
-
-	p := cm.GetOrConnect( hzAdd, "dev", "dev-pass")
+	config := hz.NewClientConfig()
+	config.Addresses = []hz.Address{{Host: "localhost", Port: 5900}}
+	config.Logger = logger
 
-	select {
-	case err := <-p.FailureChannel:
+	var err error
+	client, err = hz.NewHazelcastClient(config)
+	if err != nil {
 		logger.Fatal("Failed to connect to Hazelcast: %v", err)
-	case connection := <-p.SuccessChannel:
-		logger.Info("Connection Established: %v", connection.(*hz.ClientConnection).Address)
-		con = connection.(*hz.ClientConnection)
-		con.Logger = logger
-		con.InitReadLoop()
 	}
 
-	// Keep connection alive
-	startPinger()
-	// Get node partition info
-	hz.SendPartitions(con)
-	// Get a proxy to a queue
-	hz.SendProxyRequest(con, "myqueue", "hz:impl:queueService")
+	if con, err := client.OwnerConnection(); err == nil {
+		logger.Info("Connection Established: %v", con.Address)
+	}
+
+	logger.Info("Cluster members: %v", client.ClusterService().GetMembers())
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Get a proxy to a queue of java Strings
+	queue, err := hz.GetQueue[string](ctx, client, "myqueue")
+	if err != nil {
+		logger.Fatal("Failed to create queue proxy: %v", err)
+	}
+	// Log items as they are added and removed
+	listenerId, err := queue.AddItemListener(ctx, true, func(event hz.ItemEvent[string]) {
+		logger.Info("Item event: %d, item: %s", event.EventType, event.Item)
+	})
+	if err != nil {
+		logger.Error("Failed to add item listener: %v", err)
+	}
 	// Send a message to the queue
-	hz.SendQueuePutRequest(con, "myqueue", []byte("Hello World"))
+	if err := queue.Put(ctx, "Hello World"); err != nil {
+		logger.Error("Failed to put message: %v", err)
+	}
 	// Take a message from the queue or timeout
-	ba := hz.SendQueuePollRequest(con, "myqueue", 10000)
-	logger.Info("Received message: %s", string(ba))
+	message, ok, err := queue.Poll(ctx, 10*time.Second)
+	if err != nil {
+		logger.Error("Failed to poll message: %v", err)
+	} else if ok {
+		logger.Info("Received message: %s", message)
+	}
+	if listenerId != "" {
+		queue.RemoveItemListener(ctx, listenerId)
+	}
 	// Remove our proxy
-	hz.SendProxyDestroyRequest(con, "myqueue", "hz:impl:queueService")
+	if err := queue.Destroy(ctx); err != nil {
+		logger.Error("Failed to destroy queue proxy: %v", err)
+	}
+
+	// Get a proxy to a map of java String to Long
+	cache, err := hz.GetMap[string, int64](ctx, client, "mymap")
+	if err != nil {
+		logger.Fatal("Failed to create map proxy: %v", err)
+	}
+	if err := cache.Set(ctx, "answer", 42); err != nil {
+		logger.Error("Failed to set entry: %v", err)
+	}
+	if value, ok, err := cache.Get(ctx, "answer"); err != nil {
+		logger.Error("Failed to get entry: %v", err)
+	} else if ok {
+		logger.Info("Map entry: answer = %d", value)
+	}
 
-	// Ping a while
+	// Idle a while, the client heartbeat keeps the connection alive
 	time.Sleep( time.Second * 20)
 
-	pingStopper <- true
+	client.Shutdown()
 
 	logger.Info("demo end")
 }
\ No newline at end of file
@@ -0,0 +1,348 @@
+package hz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+
+	ErrClientShutdown = errors.New("Client is shut down")
+	ErrClusterUnreachable = errors.New("Unable to connect to any cluster address")
+)
+
+type proxyKey struct {
+
+	serviceName string
+	name        string
+}
+
+// Owns the owner connection to the cluster, replacing it with a new connection when it is lost
+type HazelcastClient struct {
+
+	config            *ClientConfig
+	connectionManager *ClientConnectionManager
+	invocationService *InvocationService
+	clusterService    *ClusterService
+
+	serializationService *SerializationService
+	credentials          Credentials
+
+	// Distributed objects created on the cluster by this client
+	proxyMutex sync.Mutex
+	proxies    map[proxyKey]bool
+
+	shutdownOnce *sync.Once
+	shutdown     chan struct{}
+
+	Logger ILogging
+}
+
+// Connect to the first reachable configured address, then load the partition table and member list
+func NewHazelcastClient(config *ClientConfig) (*HazelcastClient, error) {
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	serializationService, err := NewSerializationService(config.SerializationConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := config.credentials(serializationService)
+	if err != nil {
+		return nil, err
+	}
+
+	client := new(HazelcastClient)
+	client.config = config
+	client.serializationService = serializationService
+	client.credentials = credentials
+	client.proxies = make(map[proxyKey]bool)
+	client.Logger = config.Logger
+	if client.Logger == nil {
+		client.Logger = noLogging{}
+	}
+	client.connectionManager = &ClientConnectionManager{Logger: client.Logger}
+	client.connectionManager.HeartbeatInterval = config.HeartbeatInterval
+	client.connectionManager.HeartbeatTimeout = config.HeartbeatTimeout
+	client.connectionManager.TLSConfig = config.TLSConfig
+	client.connectionManager.MaxFrameSize = config.MaxFrameSize
+	client.connectionManager.SerializationVersion = serializationService.Version()
+	client.shutdownOnce = &sync.Once{}
+	client.shutdown = make(chan struct{})
+
+	connection, err := client.connectToCluster()
+	if err != nil {
+		return nil, err
+	}
+
+	client.invocationService = NewInvocationService(client.connectionManager, connection, config.InvocationConfig)
+	client.clusterService = NewClusterService(client.invocationService)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.ClusterStartTimeout)
+	defer cancel()
+
+	if err := client.invocationService.PartitionService().Start(ctx, config.PartitionRefreshInterval); err != nil {
+		client.Shutdown()
+		return nil, err
+	}
+	if err := client.clusterService.Start(ctx); err != nil {
+		client.Shutdown()
+		return nil, err
+	}
+
+	client.watchOwnerConnection(connection)
+
+	return client, nil
+}
+
+func (this *HazelcastClient) SerializationService() *SerializationService {
+
+	return this.serializationService
+}
+
+func (this *HazelcastClient) InvocationService() *InvocationService {
+
+	return this.invocationService
+}
+
+func (this *HazelcastClient) PartitionService() *PartitionService {
+
+	return this.invocationService.PartitionService()
+}
+
+func (this *HazelcastClient) ClusterService() *ClusterService {
+
+	return this.clusterService
+}
+
+func (this *HazelcastClient) ListenerService() *ListenerService {
+
+	return this.invocationService.ListenerService()
+}
+
+// The current owner connection, this changes when the client reconnects
+func (this *HazelcastClient) OwnerConnection() (*ClientConnection, error) {
+
+	return this.invocationService.ownerConnection()
+}
+
+// Add a queue item listener that is registered again whenever the client reconnects. Events arrive on the callback NotifyChannel,
+// Queue.AddItemListener delivers them decoded
+func (this *HazelcastClient) StartQueueListener(ctx context.Context, name string, includeValue bool) (string, *ResponseCallback, error) {
+
+	return this.ListenerService().RegisterListener(ctx, func() *ClientMessage {
+		return EncodeAddListenerRequest(name, includeValue)
+	}, RESPONSE_STRING)
+}
+
+// Deregister a listener added by StartQueueListener, on the cluster as well as locally
+func (this *HazelcastClient) StopQueueListener(ctx context.Context, name string, id string) error {
+
+	return this.ListenerService().DeregisterListener(ctx, id, func(registrationId string) *ClientMessage {
+		return EncodeQueueRemoveListenerRequest(name, registrationId)
+	})
+}
+
+// Create the distributed object on the cluster, only the first call for a name and service goes to the cluster
+func (this *HazelcastClient) createProxy(ctx context.Context, name string, serviceName string) error {
+
+	key := proxyKey{serviceName, name}
+
+	this.proxyMutex.Lock()
+	created := this.proxies[key]
+	this.proxyMutex.Unlock()
+
+	if created {
+		return nil
+	}
+
+	// Not holding the lock across the round trip, concurrent first calls may each send the request which the cluster ignores once created
+	if err := this.sendCreateProxy(ctx, name, serviceName); err != nil {
+		return err
+	}
+
+	this.proxyMutex.Lock()
+	if !this.proxies[key] {
+		this.proxies[key] = true
+		this.Logger.Trace("Proxy configured for: %s on %s", name, serviceName)
+	}
+	this.proxyMutex.Unlock()
+
+	return nil
+}
+
+func (this *HazelcastClient) sendCreateProxy(ctx context.Context, name string, serviceName string) error {
+
+	connection, err := this.OwnerConnection()
+	if err != nil {
+		return err
+	}
+
+	response, err := this.invocationService.InvokeOnConnectionWithContext(ctx, connection, EncodeProxyCreateRequest(connection, name, serviceName))
+	if err != nil {
+		return err
+	}
+
+	return checkResponse(response, RESPONSE_VOID)
+}
+
+// Create the distributed objects of this client again after a reconnect, the cluster may have restarted and lost them
+func (this *HazelcastClient) recreateProxies(ctx context.Context) {
+
+	this.proxyMutex.Lock()
+	keys := make([]proxyKey, 0, len(this.proxies))
+	for key := range this.proxies {
+		keys = append(keys, key)
+	}
+	this.proxyMutex.Unlock()
+
+	for _, key := range keys {
+		if err := this.sendCreateProxy(ctx, key.name, key.serviceName); err != nil {
+			this.Logger.Warn("Failed to recreate proxy for: %s on %s after reconnect: %v", key.name, key.serviceName, err)
+		}
+	}
+}
+
+// The partition of a partition bound object such as a queue, a name of the form name@key is placed by key as the java client does
+func (this *HazelcastClient) namePartitionId(name string) (int32, error) {
+
+	if index := strings.IndexByte(name, '@'); index >= 0 {
+		name = name[index+1:]
+	}
+
+	data, err := this.serializationService.ToData(name)
+	if err != nil {
+		return -1, err
+	}
+
+	return this.PartitionService().GetDataPartitionId(data), nil
+}
+
+// Destroy the distributed object and its data on the cluster
+func (this *HazelcastClient) destroyProxy(ctx context.Context, name string, serviceName string) error {
+
+	response, err := this.invocationService.InvokeWithContext(ctx, EncodeProxyDestroyRequest(name, serviceName))
+	if err != nil {
+		return err
+	}
+
+	if err := checkResponse(response, RESPONSE_VOID); err != nil {
+		return err
+	}
+
+	this.proxyMutex.Lock()
+	delete(this.proxies, proxyKey{serviceName, name})
+	this.proxyMutex.Unlock()
+
+	this.Logger.Trace("Proxy destroyed without error: %s on %s", name, serviceName)
+
+	return nil
+}
+
+func (this *HazelcastClient) Shutdown() {
+
+	this.shutdownOnce.Do(func() {
+		close(this.shutdown)
+		if this.invocationService != nil {
+			this.clusterService.Shutdown()
+			this.invocationService.PartitionService().Shutdown()
+		}
+		this.connectionManager.CloseAll()
+		this.Logger.Info("Client shut down")
+	})
+}
+
+// Try each configured address in turn, pausing with backoff between rounds
+func (this *HazelcastClient) connectToCluster() (*ClientConnection, error) {
+
+	pause := this.config.ConnectionRetryPause
+
+	for round := 1; ; round++ {
+
+		for _, address := range this.config.Addresses {
+			connection, err := this.connect(address)
+			if err == nil {
+				this.Logger.Info("Owner connection established: %v", connection.Address)
+				return connection, nil
+			}
+			this.Logger.Warn("Failed to connect to %v: %v", address, err)
+		}
+
+		if this.config.ConnectionAttemptLimit > 0 && round >= this.config.ConnectionAttemptLimit {
+			return nil, fmt.Errorf("%w after %d attempts: %v", ErrClusterUnreachable, round, this.config.Addresses)
+		}
+
+		select {
+		case <-this.shutdown:
+			return nil, ErrClientShutdown
+		case <-time.After(pause):
+		}
+
+		pause = time.Duration(float64(pause) * this.config.ConnectionBackoffMultiplier)
+		if pause > this.config.ConnectionMaxRetryPause {
+			pause = this.config.ConnectionMaxRetryPause
+		}
+	}
+}
+
+func (this *HazelcastClient) connect(address Address) (*ClientConnection, error) {
+
+	promise := this.connectionManager.GetOrConnectWithCredentials(address, this.credentials)
+
+	select {
+	case obj := <-promise.SuccessChannel:
+		return obj.(*ClientConnection), nil
+	case err := <-promise.FailureChannel:
+		return nil, err
+	}
+}
+
+// Reconnect when the owner connection closes, pending invocations have already failed with ErrConnectionClosed
+// and retryable ones are retried by the InvocationService once the new connection is in place
+func (this *HazelcastClient) watchOwnerConnection(connection *ClientConnection) {
+
+	go func() {
+		for {
+			select {
+			case <-this.shutdown:
+				return
+			case <-connection.closed:
+			}
+
+			select {
+			case <-this.shutdown:
+				return
+			default:
+			}
+
+			this.Logger.Warn("Owner connection to %v lost, reconnecting", connection.Address)
+
+			next, err := this.connectToCluster()
+			if err != nil {
+				this.Logger.Error("Failed to reconnect to the cluster: %v", err)
+				this.Shutdown()
+				return
+			}
+
+			this.invocationService.SetOwnerConnection(next)
+			this.PartitionService().resetStateVersion()
+
+			ctx, cancel := context.WithTimeout(context.Background(), this.config.ClusterStartTimeout)
+			this.recreateProxies(ctx)
+			this.ListenerService().reregister(ctx)
+			if err := this.PartitionService().Refresh(ctx); err != nil {
+				this.Logger.Warn("Failed to refresh partition table after reconnect: %v", err)
+			}
+			cancel()
+
+			connection = next
+		}
+	}()
+}
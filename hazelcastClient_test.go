@@ -0,0 +1,102 @@
+package hz
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConnectionAttemptLimit(t *testing.T) {
+
+	// A port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := Address{Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port}
+	listener.Close()
+
+	config := NewClientConfig()
+	config.Addresses = []Address{address}
+	config.Logger = testLogger{t}
+	config.ConnectionRetryPause = 10 * time.Millisecond
+
+	if _, err := NewHazelcastClient(config); !errors.Is(err, ErrClusterUnreachable) {
+		t.Fatalf("got %v, want ErrClusterUnreachable with the default attempt limit", err)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+
+	service, _ := NewSerializationService(nil)
+	item, _ := service.ToData("hello")
+
+	var createdProxies int32
+	stub := &stubQueue{capacity: 10}
+	stub.events = []*ClientMessage{itemEventMessage(item, "member", ITEM_ADDED)}
+
+	client, server := newConfiguredStubClient(t, func(request *ClientMessage) []*ClientMessage {
+		if request.GetMessageType() == CLIENT_CREATEPROXY {
+			atomic.AddInt32(&createdProxies, 1)
+		}
+		return stub.handle(request)
+	}, func(config *ClientConfig) {
+		config.ConnectionRetryPause = 10 * time.Millisecond
+		config.InvocationConfig.RetryPause = 10 * time.Millisecond
+	})
+	ctx := context.Background()
+
+	queue, err := GetQueue[string](ctx, client, "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan ItemEvent[string], 2)
+	if _, err := queue.AddItemChannel(ctx, true, events); err != nil {
+		t.Fatal(err)
+	}
+
+	nextEvent := func() {
+		select {
+		case event := <-events:
+			if event.Item != "hello" {
+				t.Fatalf("got %+v", event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no item event")
+		}
+	}
+	nextEvent()
+
+	before, _ := client.OwnerConnection()
+	server.closeConnections()
+
+	// The stub sends an event each time the listener is added, so this one arrives through the registration on the new connection
+	nextEvent()
+
+	after, err := client.OwnerConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after == before {
+		t.Fatal("owner connection not replaced")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&createdProxies) < 2 || atomic.LoadInt32(&server.memberListeners) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("proxies created: %d, membership listeners: %d", atomic.LoadInt32(&createdProxies), atomic.LoadInt32(&server.memberListeners))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := queue.Offer(ctx, "world", 0); err != nil {
+		t.Fatal(err)
+	}
+	if size, err := queue.Size(ctx); err != nil || size != 1 {
+		t.Fatalf("size: %d, %v", size, err)
+	}
+}
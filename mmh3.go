@@ -66,6 +66,21 @@ func hash32(key []byte, seed int32) int32 {
 
 func CalcHash(connection *ClientConnection, key [] byte) int32 {
 
+	partitionCount := connection.partitionCount.Load()
+	hash := partitionIdForKey(key, partitionCount)
+
+	connection.Logger.Trace("### Hash Calc: partition count: %d, hash: %d", partitionCount, hash)
+
+	return hash
+}
+
+// -1 when the partition count is not yet known, the invocation then goes to any member
+func partitionIdForKey(key [] byte, partitionCount int32) int32 {
+
+	if partitionCount <= 0 {
+		return -1
+	}
+
 	// To determine the partition ID of an operation, compute the Murmur Hash (version 3, 32-bit, see https://en.wikipedia.org/wiki/MurmurHash and http s://code.google.com/p/smhasher/wiki/MurmurHash3)
 	// of a certain byte-array (which is identified for each message description section) and take the modulus of the result over the total number of partitions. The seed for the Murmur Hash SHOULD
 	// be 0x01000193. Most operations with a key parameter use the key parameter byte-array as the data for the hash calculation.
@@ -79,9 +94,6 @@ func CalcHash(connection *ClientConnection, key [] byte) int32 {
 			av = -av
 		}
 	}
-	hash := int32(av % connection.partitionCount)
-
-	connection.Logger.Trace("### Hash Calc: murmur3: %d, partition count: %d, hash: %d", av, connection.partitionCount, hash)
 
-	return hash
-}
\ No newline at end of file
+	return int32(av % partitionCount)
+}
@@ -0,0 +1,499 @@
+/*
+Predicates serialized as the java predicate classes, for the hz.Map query methods. Attributes name a field or getter of the java
+value, "__key" the key and "this" the value itself. Comparison values are serialized with the client SerializationService and
+converted by the cluster to the attribute type, so a Go int (a java Long) can be compared with an Integer attribute.
+*/
+package predicates
+
+import (
+	".."
+	"fmt"
+)
+
+/* Class ids of the java predicates, com.hazelcast.query.impl.predicates.PredicateDataSerializerHook */
+const (
+
+	SQL_PREDICATE = 0
+	AND_PREDICATE = 1
+	BETWEEN_PREDICATE = 2
+	EQUAL_PREDICATE = 3
+	GREATERLESS_PREDICATE = 4
+	LIKE_PREDICATE = 5
+	ILIKE_PREDICATE = 6
+	IN_PREDICATE = 7
+	INSTANCEOF_PREDICATE = 8
+	NOTEQUAL_PREDICATE = 9
+	NOT_PREDICATE = 10
+	OR_PREDICATE = 11
+	REGEX_PREDICATE = 12
+	FALSE_PREDICATE = 13
+	TRUE_PREDICATE = 14
+)
+
+// A predicate in the java SQL like syntax, e.g. "active AND age > 30"
+func Sql(sql string) hz.Predicate {
+
+	return &sqlPredicate{sql: sql}
+}
+
+// True when all the predicates are true
+func And(predicates ...hz.Predicate) hz.Predicate {
+
+	return &andPredicate{predicates: predicates}
+}
+
+// True when any of the predicates is true
+func Or(predicates ...hz.Predicate) hz.Predicate {
+
+	return &orPredicate{predicates: predicates}
+}
+
+func Not(predicate hz.Predicate) hz.Predicate {
+
+	return &notPredicate{predicate: predicate}
+}
+
+// Inclusive of from and to
+func Between(attribute string, from interface{}, to interface{}) hz.Predicate {
+
+	return &betweenPredicate{attribute: attribute, from: from, to: to}
+}
+
+func Equal(attribute string, value interface{}) hz.Predicate {
+
+	return &equalPredicate{attribute: attribute, value: value}
+}
+
+func NotEqual(attribute string, value interface{}) hz.Predicate {
+
+	return &notEqualPredicate{equalPredicate{attribute: attribute, value: value}}
+}
+
+func GreaterThan(attribute string, value interface{}) hz.Predicate {
+
+	return &greaterLessPredicate{attribute: attribute, value: value, equal: false, less: false}
+}
+
+func GreaterEqual(attribute string, value interface{}) hz.Predicate {
+
+	return &greaterLessPredicate{attribute: attribute, value: value, equal: true, less: false}
+}
+
+func LessThan(attribute string, value interface{}) hz.Predicate {
+
+	return &greaterLessPredicate{attribute: attribute, value: value, equal: false, less: true}
+}
+
+func LessEqual(attribute string, value interface{}) hz.Predicate {
+
+	return &greaterLessPredicate{attribute: attribute, value: value, equal: true, less: true}
+}
+
+// True when the attribute equals any of the values
+func In(attribute string, values ...interface{}) hz.Predicate {
+
+	return &inPredicate{attribute: attribute, values: values}
+}
+
+// An SQL LIKE expression, % matches any characters and _ a single character
+func Like(attribute string, expression string) hz.Predicate {
+
+	return &likePredicate{attribute: attribute, expression: expression}
+}
+
+// A case insensitive Like
+func ILike(attribute string, expression string) hz.Predicate {
+
+	return &iLikePredicate{likePredicate{attribute: attribute, expression: expression}}
+}
+
+// A java regular expression matched against the whole attribute
+func Regex(attribute string, regex string) hz.Predicate {
+
+	return &regexPredicate{attribute: attribute, regex: regex}
+}
+
+// True when the map value is an instance of the java class
+func InstanceOf(className string) hz.Predicate {
+
+	return &instanceOfPredicate{className: className}
+}
+
+func True() hz.Predicate {
+
+	return &truePredicate{}
+}
+
+func False() hz.Predicate {
+
+	return &falsePredicate{}
+}
+
+// Predicates read from the stream are created by the hz predicate factory
+func init() {
+
+	hz.RegisterPredicateClass(SQL_PREDICATE, func() hz.IdentifiedDataSerializable { return &sqlPredicate{} })
+	hz.RegisterPredicateClass(AND_PREDICATE, func() hz.IdentifiedDataSerializable { return &andPredicate{} })
+	hz.RegisterPredicateClass(BETWEEN_PREDICATE, func() hz.IdentifiedDataSerializable { return &betweenPredicate{} })
+	hz.RegisterPredicateClass(EQUAL_PREDICATE, func() hz.IdentifiedDataSerializable { return &equalPredicate{} })
+	hz.RegisterPredicateClass(GREATERLESS_PREDICATE, func() hz.IdentifiedDataSerializable { return &greaterLessPredicate{} })
+	hz.RegisterPredicateClass(LIKE_PREDICATE, func() hz.IdentifiedDataSerializable { return &likePredicate{} })
+	hz.RegisterPredicateClass(ILIKE_PREDICATE, func() hz.IdentifiedDataSerializable { return &iLikePredicate{} })
+	hz.RegisterPredicateClass(IN_PREDICATE, func() hz.IdentifiedDataSerializable { return &inPredicate{} })
+	hz.RegisterPredicateClass(INSTANCEOF_PREDICATE, func() hz.IdentifiedDataSerializable { return &instanceOfPredicate{} })
+	hz.RegisterPredicateClass(NOTEQUAL_PREDICATE, func() hz.IdentifiedDataSerializable { return &notEqualPredicate{} })
+	hz.RegisterPredicateClass(NOT_PREDICATE, func() hz.IdentifiedDataSerializable { return &notPredicate{} })
+	hz.RegisterPredicateClass(OR_PREDICATE, func() hz.IdentifiedDataSerializable { return &orPredicate{} })
+	hz.RegisterPredicateClass(REGEX_PREDICATE, func() hz.IdentifiedDataSerializable { return &regexPredicate{} })
+	hz.RegisterPredicateClass(FALSE_PREDICATE, func() hz.IdentifiedDataSerializable { return &falsePredicate{} })
+	hz.RegisterPredicateClass(TRUE_PREDICATE, func() hz.IdentifiedDataSerializable { return &truePredicate{} })
+}
+
+/* Payload: the sql string */
+type sqlPredicate struct {
+
+	sql string
+}
+
+func (*sqlPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*sqlPredicate) ClassID() int32   { return SQL_PREDICATE }
+
+func (this *sqlPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	output.WriteUTF(this.sql)
+	return nil
+}
+
+func (this *sqlPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	this.sql = input.ReadUTF()
+	return input.Error()
+}
+
+func (this *sqlPredicate) String() string {
+
+	return this.sql
+}
+
+/* Payload: the predicate count then each predicate as an object */
+type andPredicate struct {
+
+	predicates []hz.Predicate
+}
+
+func (*andPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*andPredicate) ClassID() int32   { return AND_PREDICATE }
+
+func (this *andPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	return writePredicates(output, this.predicates)
+}
+
+func (this *andPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	var err error
+	this.predicates, err = readPredicates(input)
+	return err
+}
+
+type orPredicate struct {
+
+	predicates []hz.Predicate
+}
+
+func (*orPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*orPredicate) ClassID() int32   { return OR_PREDICATE }
+
+func (this *orPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	return writePredicates(output, this.predicates)
+}
+
+func (this *orPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	var err error
+	this.predicates, err = readPredicates(input)
+	return err
+}
+
+/* Payload: the predicate as an object */
+type notPredicate struct {
+
+	predicate hz.Predicate
+}
+
+func (*notPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*notPredicate) ClassID() int32   { return NOT_PREDICATE }
+
+func (this *notPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	return output.WriteObject(this.predicate)
+}
+
+func (this *notPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	var err error
+	this.predicate, err = hz.ReadPredicate(input)
+	return err
+}
+
+/* Payload: the attribute, to then from as objects */
+type betweenPredicate struct {
+
+	attribute string
+	from      interface{}
+	to        interface{}
+}
+
+func (*betweenPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*betweenPredicate) ClassID() int32   { return BETWEEN_PREDICATE }
+
+func (this *betweenPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	output.WriteUTF(this.attribute)
+	if err := output.WriteObject(this.to); err != nil {
+		return err
+	}
+
+	return output.WriteObject(this.from)
+}
+
+func (this *betweenPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	this.attribute = input.ReadUTF()
+	this.to = input.ReadObject()
+	this.from = input.ReadObject()
+	return input.Error()
+}
+
+/* Payload: the attribute and value as an object */
+type equalPredicate struct {
+
+	attribute string
+	value     interface{}
+}
+
+func (*equalPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*equalPredicate) ClassID() int32   { return EQUAL_PREDICATE }
+
+func (this *equalPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	output.WriteUTF(this.attribute)
+	return output.WriteObject(this.value)
+}
+
+func (this *equalPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	this.attribute = input.ReadUTF()
+	this.value = input.ReadObject()
+	return input.Error()
+}
+
+/* Payload as equalPredicate */
+type notEqualPredicate struct {
+
+	equalPredicate
+}
+
+func (*notEqualPredicate) ClassID() int32 { return NOTEQUAL_PREDICATE }
+
+/* Payload: the attribute, value as an object, then the equal and less flags */
+type greaterLessPredicate struct {
+
+	attribute string
+	value     interface{}
+	equal     bool
+	less      bool
+}
+
+func (*greaterLessPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*greaterLessPredicate) ClassID() int32   { return GREATERLESS_PREDICATE }
+
+func (this *greaterLessPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	output.WriteUTF(this.attribute)
+	if err := output.WriteObject(this.value); err != nil {
+		return err
+	}
+	output.WriteBool(this.equal)
+	output.WriteBool(this.less)
+
+	return nil
+}
+
+func (this *greaterLessPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	this.attribute = input.ReadUTF()
+	this.value = input.ReadObject()
+	this.equal = input.ReadBool()
+	this.less = input.ReadBool()
+	return input.Error()
+}
+
+/* Payload: the attribute, the value count then each value as an object */
+type inPredicate struct {
+
+	attribute string
+	values    []interface{}
+}
+
+func (*inPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*inPredicate) ClassID() int32   { return IN_PREDICATE }
+
+func (this *inPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	output.WriteUTF(this.attribute)
+	output.WriteInt32(int32(len(this.values)))
+	for _, value := range this.values {
+		if err := output.WriteObject(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (this *inPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	this.attribute = input.ReadUTF()
+	count := input.ReadInt32()
+	if input.Error() != nil {
+		return input.Error()
+	}
+	if count < 0 || int(count) > input.Available() {
+		return fmt.Errorf("%w: in predicate value count %d", hz.ErrInvalidData, count)
+	}
+
+	this.values = make([]interface{}, count)
+	for i := range this.values {
+		this.values[i] = input.ReadObject()
+	}
+
+	return input.Error()
+}
+
+/* Payload: the attribute and the expression */
+type likePredicate struct {
+
+	attribute  string
+	expression string
+}
+
+func (*likePredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*likePredicate) ClassID() int32   { return LIKE_PREDICATE }
+
+func (this *likePredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	output.WriteUTF(this.attribute)
+	output.WriteUTF(this.expression)
+	return nil
+}
+
+func (this *likePredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	this.attribute = input.ReadUTF()
+	this.expression = input.ReadUTF()
+	return input.Error()
+}
+
+/* Payload as likePredicate */
+type iLikePredicate struct {
+
+	likePredicate
+}
+
+func (*iLikePredicate) ClassID() int32 { return ILIKE_PREDICATE }
+
+/* Payload: the attribute and the regex */
+type regexPredicate struct {
+
+	attribute string
+	regex     string
+}
+
+func (*regexPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*regexPredicate) ClassID() int32   { return REGEX_PREDICATE }
+
+func (this *regexPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	output.WriteUTF(this.attribute)
+	output.WriteUTF(this.regex)
+	return nil
+}
+
+func (this *regexPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	this.attribute = input.ReadUTF()
+	this.regex = input.ReadUTF()
+	return input.Error()
+}
+
+/* Payload: the java class name */
+type instanceOfPredicate struct {
+
+	className string
+}
+
+func (*instanceOfPredicate) FactoryID() int32 { return hz.PREDICATE_FACTORY_ID }
+func (*instanceOfPredicate) ClassID() int32   { return INSTANCEOF_PREDICATE }
+
+func (this *instanceOfPredicate) WriteData(output *hz.ObjectDataOutput) error {
+
+	output.WriteUTF(this.className)
+	return nil
+}
+
+func (this *instanceOfPredicate) ReadData(input *hz.ObjectDataInput) error {
+
+	this.className = input.ReadUTF()
+	return input.Error()
+}
+
+/* No payload */
+type truePredicate struct{}
+
+func (*truePredicate) FactoryID() int32                  { return hz.PREDICATE_FACTORY_ID }
+func (*truePredicate) ClassID() int32                    { return TRUE_PREDICATE }
+func (*truePredicate) WriteData(*hz.ObjectDataOutput) error { return nil }
+func (*truePredicate) ReadData(*hz.ObjectDataInput) error   { return nil }
+
+type falsePredicate struct{}
+
+func (*falsePredicate) FactoryID() int32                  { return hz.PREDICATE_FACTORY_ID }
+func (*falsePredicate) ClassID() int32                    { return FALSE_PREDICATE }
+func (*falsePredicate) WriteData(*hz.ObjectDataOutput) error { return nil }
+func (*falsePredicate) ReadData(*hz.ObjectDataInput) error   { return nil }
+
+func writePredicates(output *hz.ObjectDataOutput, predicates []hz.Predicate) error {
+
+	output.WriteInt32(int32(len(predicates)))
+	for _, predicate := range predicates {
+		if err := output.WriteObject(predicate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readPredicates(input *hz.ObjectDataInput) ([]hz.Predicate, error) {
+
+	count := input.ReadInt32()
+	if input.Error() != nil {
+		return nil, input.Error()
+	}
+	if count < 0 || int(count) > input.Available() {
+		return nil, fmt.Errorf("%w: predicate count %d", hz.ErrInvalidData, count)
+	}
+
+	predicates := make([]hz.Predicate, count)
+	for i := range predicates {
+		predicate, err := hz.ReadPredicate(input)
+		if err != nil {
+			return nil, err
+		}
+		predicates[i] = predicate
+	}
+
+	return predicates, nil
+}
@@ -0,0 +1,113 @@
+package predicates
+
+import (
+	".."
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestPredicateRoundTrip(t *testing.T) {
+
+	service, err := hz.NewSerializationService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paging, err := hz.NewPagingPredicate(Equal("age", int32(5)), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		predicate hz.Predicate
+		classId   int32
+	}{
+		{"sql", Sql("active AND age > 3"), SQL_PREDICATE},
+		{"and", And(True(), Not(False())), AND_PREDICATE},
+		{"or", Or(Like("name", "a%"), ILike("name", "B%")), OR_PREDICATE},
+		{"between", Between("age", int32(1), int32(9)), BETWEEN_PREDICATE},
+		{"equal", Equal("age", "x"), EQUAL_PREDICATE},
+		{"not equal", NotEqual("age", "x"), NOTEQUAL_PREDICATE},
+		{"greater than", GreaterThan("age", 3.5), GREATERLESS_PREDICATE},
+		{"less equal", LessEqual("age", int64(3)), GREATERLESS_PREDICATE},
+		{"in", In("id", int32(1), "two", nil), IN_PREDICATE},
+		{"like", Like("name", "a%"), LIKE_PREDICATE},
+		{"ilike", ILike("name", "a%"), ILIKE_PREDICATE},
+		{"regex", Regex("name", "^a.*"), REGEX_PREDICATE},
+		{"instance of", InstanceOf("com.acme.Person"), INSTANCEOF_PREDICATE},
+		{"true", True(), TRUE_PREDICATE},
+		{"false", False(), FALSE_PREDICATE},
+		{"paging", paging, hz.PAGING_PREDICATE},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			if test.predicate.FactoryID() != hz.PREDICATE_FACTORY_ID || test.predicate.ClassID() != test.classId {
+				t.Fatalf("ids: got %d/%d, want %d/%d", test.predicate.FactoryID(), test.predicate.ClassID(), hz.PREDICATE_FACTORY_ID, test.classId)
+			}
+
+			data, err := service.ToData(test.predicate)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			object, err := service.ToObject(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reflect.TypeOf(object) != reflect.TypeOf(test.predicate) {
+				t.Fatalf("got %T, want %T", object, test.predicate)
+			}
+
+			again, err := service.ToData(object)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(again, data) {
+				t.Fatalf("read back as %#v", object)
+			}
+		})
+	}
+}
+
+func TestPredicateBytes(t *testing.T) {
+
+	service, _ := hz.NewSerializationService(nil)
+
+	tests := []struct {
+		name      string
+		predicate hz.Predicate
+		payload   string
+	}{
+		// attribute then the value as an Integer object
+		{"equal", Equal("age", int32(5)), "00000003616765" + "fffffff9" + "00000005"},
+		// attribute, value, then the equal and less flags
+		{"greater equal", GreaterEqual("a", int32(1)), "0000000161" + "fffffff9" + "00000001" + "01" + "00"},
+		// to is written before from
+		{"between", Between("a", int32(1), int32(2)), "0000000161" + "fffffff9" + "00000002" + "fffffff9" + "00000001"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			data, err := service.ToData(test.predicate)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// header, identified flag, factory id and class id precede the payload
+			header := "00000000" + "fffffffe" + "01" + "ffffffe0" + hex.EncodeToString([]byte{0, 0, 0, byte(test.predicate.ClassID())})
+			want, _ := hex.DecodeString(header + test.payload)
+
+			if !bytes.Equal(data, want) {
+				t.Fatalf("got %x, want %x", []byte(data), want)
+			}
+		})
+	}
+}